@@ -0,0 +1,13 @@
+package admin
+
+// Config is the configuration for the admin server.
+// It must only be reachable from inside the cluster.
+type Config struct {
+	Enable bool   `envconfig:"enable"`
+	Addr   string `envconfig:"addr" default:"127.0.0.1:9091"`
+	// Username and Password enable basic auth.
+	Username string `envconfig:"username"`
+	Password string `envconfig:"password"`
+	// Token enables bearer token auth.
+	Token string `envconfig:"token"`
+}
@@ -0,0 +1,77 @@
+package admin
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/widnyana/wasabi/internal/adapter/redact"
+)
+
+// ConfigSnapshot carries the application config to the admin server.
+// It is provided by the config module to avoid an import cycle.
+type ConfigSnapshot struct {
+	Value any
+}
+
+// Redact converts a config struct into nested maps keyed by envconfig names,
+// replacing the values of fields the redaction policy marks as sensitive.
+func Redact(policy *redact.Policy, value any) any {
+	return redactValue(policy, reflect.ValueOf(value))
+}
+
+func redactValue(policy *redact.Policy, v reflect.Value) any {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		out := map[string]any{}
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			name := fieldName(field)
+			if (policy.Field(field.Name) || policy.Field(name)) && !v.Field(i).IsZero() {
+				out[name] = redact.Redacted
+				continue
+			}
+
+			out[name] = redactValue(policy, v.Field(i))
+		}
+		return out
+	case reflect.Map:
+		out := map[string]any{}
+		iter := v.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			if policy.Field(key) {
+				out[key] = redact.Redacted
+				continue
+			}
+			out[key] = redactValue(policy, iter.Value())
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		out := make([]any, v.Len())
+		for i := range v.Len() {
+			out[i] = redactValue(policy, v.Index(i))
+		}
+		return out
+	default:
+		return v.Interface()
+	}
+}
+
+func fieldName(field reflect.StructField) string {
+	if tag, ok := field.Tag.Lookup("envconfig"); ok && tag != "" {
+		return tag
+	}
+
+	return strings.ToLower(field.Name)
+}
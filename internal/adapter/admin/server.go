@@ -0,0 +1,228 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/pprof"
+	"os/exec"
+	runtimepprof "runtime/pprof"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"github.com/widnyana/wasabi/internal/adapter/health"
+	"github.com/widnyana/wasabi/internal/adapter/logger"
+	"github.com/widnyana/wasabi/internal/adapter/redact"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// ReadHeaderTimeout is the maximum amount of time to allow reading request headers.
+const ReadHeaderTimeout = 5
+
+type (
+	// Server is the admin HTTP server. It is a distinct type so it does not
+	// collide with metrics.Server in the container.
+	Server struct {
+		*http.Server
+	}
+
+	// Route is an extra admin endpoint contributed to the "admin_routes" value group.
+	Route struct {
+		Pattern string
+		Handler http.Handler
+	}
+
+	// Params holds the dependencies of the admin server.
+	Params struct {
+		fx.In
+
+		Config   Config
+		Levels   *logger.Levels
+		Graph    fx.DotGraph
+		Health   *health.Registry
+		Policy   *redact.Policy
+		App      *fiber.App      `optional:"true"`
+		Snapshot *ConfigSnapshot `optional:"true"`
+		Routes   []Route         `group:"admin_routes"`
+	}
+
+	routeInfo struct {
+		Method string `json:"method"`
+		Path   string `json:"path"`
+		Name   string `json:"name,omitempty"`
+	}
+)
+
+// Module is the fx module for the admin server.
+var Module = fx.Module(
+	"admin",
+	fx.Provide(NewServer),
+	fx.Invoke(HookAdminServer),
+)
+
+// AsRoute annotates a constructor returning a Route so it joins the "admin_routes" value group.
+func AsRoute(constructor any) any {
+	return fx.Annotate(constructor, fx.ResultTags(`group:"admin_routes"`))
+}
+
+// NewServer creates the admin server. It serves operational endpoints that
+// don't belong on the public Fiber port.
+func NewServer(params Params) *Server {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/goroutines", serveGoroutines)
+
+	mux.Handle("/loglevel", params.Levels)
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		report := params.Health.Report(r.Context())
+		status := http.StatusOK
+		if report.Status != health.StatusUp {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, report)
+	})
+	mux.HandleFunc("/routes", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, routesOf(params.App))
+	})
+	mux.HandleFunc("/config", func(w http.ResponseWriter, _ *http.Request) {
+		if params.Snapshot == nil {
+			http.Error(w, "config snapshot is not available", http.StatusNotFound)
+			return
+		}
+		// Without a policy nothing would be hidden, so the config is not served.
+		if params.Policy == nil {
+			http.Error(w, "config is not served while redaction is disabled", http.StatusForbidden)
+			return
+		}
+		writeJSON(w, http.StatusOK, Redact(params.Policy, params.Snapshot.Value))
+	})
+	mux.HandleFunc("/graph", func(w http.ResponseWriter, r *http.Request) {
+		serveGraph(w, r, params.Graph)
+	})
+
+	for _, route := range params.Routes {
+		mux.Handle(route.Pattern, route.Handler)
+	}
+
+	return &Server{&http.Server{
+		Addr:              params.Config.Addr,
+		Handler:           authorize(mux, params.Config),
+		ReadHeaderTimeout: ReadHeaderTimeout * time.Second,
+	}}
+}
+
+// HookAdminServer hooks the admin server to the fx lifecycle when it is enabled.
+func HookAdminServer(lifecycle fx.Lifecycle, cfg Config, server *Server, log *otelzap.Logger) {
+	if !cfg.Enable {
+		return
+	}
+
+	lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				err := server.ListenAndServe()
+				if err != nil && !errors.Is(err, http.ErrServerClosed) {
+					log.Fatal("failed to start admin server", zap.Error(err))
+				}
+			}()
+			log.Info("admin server started", zap.String("addr", server.Addr))
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return server.Shutdown(ctx)
+		},
+	})
+}
+
+// authorize requires either the configured basic auth credentials or bearer token.
+// Without credentials configured the endpoints are open.
+func authorize(next http.Handler, cfg Config) http.Handler {
+	if cfg.Username == "" && cfg.Token == "" {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); ok && cfg.Username != "" &&
+			subtle.ConstantTimeCompare([]byte(username), []byte(cfg.Username)) == 1 &&
+			subtle.ConstantTimeCompare([]byte(password), []byte(cfg.Password)) == 1 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && cfg.Token != "" &&
+			subtle.ConstantTimeCompare([]byte(token), []byte(cfg.Token)) == 1 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("WWW-Authenticate", `Basic realm="admin"`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	})
+}
+
+func serveGoroutines(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_ = runtimepprof.Lookup("goroutine").WriteTo(w, 2)
+}
+
+// serveGraph writes the fx dependency graph as DOT, or as SVG when
+// ?format=svg is requested and graphviz is installed.
+func serveGraph(w http.ResponseWriter, r *http.Request, graph fx.DotGraph) {
+	if r.URL.Query().Get("format") != "svg" {
+		w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+		_, _ = w.Write([]byte(graph))
+		return
+	}
+
+	dot, err := exec.LookPath("dot")
+	if err != nil {
+		http.Error(w, "graphviz is not installed, use ?format=dot", http.StatusNotImplemented)
+		return
+	}
+
+	var svg bytes.Buffer
+	cmd := exec.CommandContext(r.Context(), dot, "-Tsvg")
+	cmd.Stdin = strings.NewReader(string(graph))
+	cmd.Stdout = &svg
+	if err := cmd.Run(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	_, _ = w.Write(svg.Bytes())
+}
+
+func routesOf(app *fiber.App) []routeInfo {
+	if app == nil {
+		return []routeInfo{}
+	}
+
+	routes := app.GetRoutes(true)
+	infos := make([]routeInfo, 0, len(routes))
+	for _, route := range routes {
+		if route.Method == fiber.MethodHead {
+			continue
+		}
+		infos = append(infos, routeInfo{Method: route.Method, Path: route.Path, Name: route.Name})
+	}
+
+	return infos
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bytedance/sonic"
+	grds "github.com/redis/go-redis/v9"
+)
+
+const (
+	cacheKeyPrefix = "wasabi:apikey:"
+
+	// cacheTombstone replaces a revoked key in the cache for one TTL, so an
+	// Authenticate that read the key before it was revoked cannot cache it again.
+	cacheTombstone = "revoked"
+)
+
+// keyCache caches resolved API keys in Redis. A nil client disables caching.
+type keyCache struct {
+	client *grds.Client
+	ttl    time.Duration
+}
+
+func (cache keyCache) get(ctx context.Context, hash string) (*APIKey, bool) {
+	if cache.client == nil {
+		return nil, false
+	}
+
+	raw, err := cache.client.Get(ctx, cacheKeyPrefix+hash).Bytes()
+	if err != nil || string(raw) == cacheTombstone {
+		return nil, false
+	}
+
+	var key APIKey
+	if err := sonic.Unmarshal(raw, &key); err != nil {
+		return nil, false
+	}
+
+	// Hash is not serialized, restore it so the cached key can be invalidated.
+	key.Hash = hash
+	return &key, true
+}
+
+func (cache keyCache) set(ctx context.Context, key *APIKey) error {
+	if cache.client == nil || cache.ttl <= 0 {
+		return nil
+	}
+
+	raw, err := sonic.Marshal(key)
+	if err != nil {
+		return err
+	}
+
+	// NX keeps a tombstone written by a concurrent delete.
+	return cache.client.SetNX(ctx, cacheKeyPrefix+key.Hash, raw, cache.ttl).Err()
+}
+
+// delete evicts a key and leaves a tombstone in its place until the TTL
+// expires, see cacheTombstone.
+func (cache keyCache) delete(ctx context.Context, hash string) error {
+	if cache.client == nil {
+		return nil
+	}
+
+	if cache.ttl > 0 {
+		return cache.client.Set(ctx, cacheKeyPrefix+hash, cacheTombstone, cache.ttl).Err()
+	}
+
+	err := cache.client.Del(ctx, cacheKeyPrefix+hash).Err()
+	if errors.Is(err, grds.Nil) {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/widnyana/wasabi/internal/adapter/cli"
+)
+
+var errMissingFlag = errors.New("missing required flag")
+
+// NewAPIKeyCommand creates the `apikey` command with its create, list and revoke subcommands.
+func NewAPIKeyCommand(svc *APIKeyService) *cli.Command {
+	return &cli.Command{
+		Name:  "apikey",
+		Short: "manage API keys",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "create",
+				Usage: "-name <name> -owner <owner> [-scopes a,b] [-ttl 720h]",
+				Short: "create an API key and print it once",
+				Run:   func(ctx context.Context, args []string) error { return createAPIKey(ctx, svc, os.Stdout, args) },
+			},
+			{
+				Name:  "list",
+				Usage: "[-owner <owner>]",
+				Short: "list API keys",
+				Run:   func(ctx context.Context, args []string) error { return listAPIKeys(ctx, svc, os.Stdout, args) },
+			},
+			{
+				Name:  "revoke",
+				Usage: "<id>",
+				Short: "revoke an API key",
+				Run:   func(ctx context.Context, args []string) error { return revokeAPIKey(ctx, svc, os.Stdout, args) },
+			},
+		},
+	}
+}
+
+func createAPIKey(ctx context.Context, svc *APIKeyService, out io.Writer, args []string) error {
+	flags := flag.NewFlagSet("apikey create", flag.ContinueOnError)
+	name := flags.String("name", "", "human readable key name")
+	owner := flags.String("owner", "", "owner of the key")
+	scopes := flags.String("scopes", "", "comma separated scopes")
+	ttl := flags.Duration("ttl", 0, "key lifetime, 0 means no expiry")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *name == "" || *owner == "" {
+		return fmt.Errorf("%w: -name and -owner", errMissingFlag)
+	}
+
+	params := CreateAPIKeyParams{Name: *name, Owner: *owner}
+	for _, scope := range strings.Split(*scopes, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			params.Scopes = append(params.Scopes, scope)
+		}
+	}
+
+	if *ttl > 0 {
+		expiresAt := time.Now().Add(*ttl).UTC()
+		params.ExpiresAt = &expiresAt
+	}
+
+	plain, key, err := svc.Create(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(out, "id:  %s\nkey: %s\n\nStore the key now, it cannot be shown again.\n", key.ID, plain)
+	return err
+}
+
+func listAPIKeys(ctx context.Context, svc *APIKeyService, out io.Writer, args []string) error {
+	flags := flag.NewFlagSet("apikey list", flag.ContinueOnError)
+	owner := flags.String("owner", "", "only list keys of this owner")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	keys, err := svc.List(ctx, *owner)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ID\tPREFIX\tNAME\tOWNER\tSCOPES\tEXPIRES\tLAST USED\tREVOKED")
+	for _, key := range keys {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			key.ID, key.Prefix, key.Name, key.Owner, strings.Join(key.Scopes, ","),
+			formatTime(key.ExpiresAt), formatTime(key.LastUsedAt), formatTime(key.RevokedAt),
+		)
+	}
+
+	return w.Flush()
+}
+
+func revokeAPIKey(ctx context.Context, svc *APIKeyService, out io.Writer, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: expected exactly one key id", errMissingFlag)
+	}
+
+	if err := svc.Revoke(ctx, args[0]); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(out, "revoked %s\n", args[0])
+	return err
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+
+	return t.Format(time.RFC3339)
+}
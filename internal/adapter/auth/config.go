@@ -0,0 +1,15 @@
+package auth
+
+import "time"
+
+// Config represents the configuration for the auth module.
+type Config struct {
+	// KeyPrefix is prepended to generated API keys so they are easy to spot in leaks.
+	KeyPrefix string `envconfig:"key_prefix" default:"wsb"`
+	// Pepper is the HMAC secret mixed into stored key hashes. It is required.
+	Pepper string `envconfig:"pepper"`
+	// CacheTTL is how long a resolved key stays in Redis.
+	CacheTTL time.Duration `envconfig:"cache_ttl" default:"5m"`
+	// LastUsedInterval throttles last_used_at updates per key.
+	LastUsedInterval time.Duration `envconfig:"last_used_interval" default:"1m"`
+}
@@ -0,0 +1,11 @@
+package auth
+
+import "errors"
+
+var (
+	ErrInvalidAPIKey  = errors.New("invalid api key")
+	ErrAPIKeyExpired  = errors.New("api key has expired")
+	ErrAPIKeyRevoked  = errors.New("api key has been revoked")
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrEmptyPepper    = errors.New("auth pepper must be set")
+)
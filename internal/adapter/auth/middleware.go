@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	applog "github.com/widnyana/wasabi/internal/adapter/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	headerAPIKey       = "X-API-Key"
+	authSchemeAPIKey   = "ApiKey"
+	headerWWWAuthorize = "WWW-Authenticate"
+)
+
+// APIKeyMiddleware resolves the `Authorization: ApiKey <key>` or `X-API-Key`
+// header into a principal. Requests without an API key pass through untouched
+// so other auth methods can handle them. Rejected keys get the same generic
+// 401 whatever the reason, so callers cannot probe which keys exist; the
+// reason is only logged.
+func APIKeyMiddleware(svc *APIKeyService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		plain := apiKeyFromRequest(ctx)
+		if plain == "" {
+			return ctx.Next()
+		}
+
+		principal, err := svc.Authenticate(ctx.UserContext(), plain)
+		switch {
+		case errors.Is(err, ErrInvalidAPIKey), errors.Is(err, ErrAPIKeyExpired), errors.Is(err, ErrAPIKeyRevoked):
+			applog.Ctx(svc.logger, ctx.UserContext()).Info("api key rejected", zap.Error(err))
+			ctx.Set(headerWWWAuthorize, authSchemeAPIKey)
+			return fiber.NewError(fiber.StatusUnauthorized, ErrInvalidAPIKey.Error())
+		case err != nil:
+			return err
+		}
+
+		SetPrincipal(ctx, principal)
+		return ctx.Next()
+	}
+}
+
+// RequireScopes rejects requests without a principal, or whose principal lacks any of the scopes.
+func RequireScopes(scopes ...string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		principal, ok := PrincipalFromFiber(ctx)
+		if !ok {
+			return fiber.ErrUnauthorized
+		}
+
+		for _, scope := range scopes {
+			if !principal.HasScope(scope) {
+				return fiber.NewError(fiber.StatusForbidden, "missing scope "+scope)
+			}
+		}
+
+		return ctx.Next()
+	}
+}
+
+func apiKeyFromRequest(ctx *fiber.Ctx) string {
+	if key := ctx.Get(headerAPIKey); key != "" {
+		return strings.TrimSpace(key)
+	}
+
+	scheme, key, ok := strings.Cut(ctx.Get(fiber.HeaderAuthorization), " ")
+	if ok && strings.EqualFold(scheme, authSchemeAPIKey) {
+		return strings.TrimSpace(key)
+	}
+
+	return ""
+}
@@ -0,0 +1,18 @@
+package auth
+
+import (
+	"github.com/widnyana/wasabi/internal/adapter/cli"
+	"go.uber.org/fx"
+)
+
+// Module provides the API key service. Its api_keys table is created by
+// the migrations of the migrate package.
+var (
+	Module = fx.Module("auth", Providers)
+
+	Providers = fx.Options(
+		fx.Provide(NewStore),
+		fx.Provide(NewAPIKeyService),
+		fx.Provide(cli.AsCommand(NewAPIKeyCommand)),
+	)
+)
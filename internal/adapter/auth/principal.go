@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"context"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	applog "github.com/widnyana/wasabi/internal/adapter/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// KindAPIKey marks a principal authenticated with an API key.
+	KindAPIKey = "api_key"
+
+	localsPrincipal = "auth.principal"
+)
+
+type principalCtxKey struct{}
+
+// Principal is the authenticated caller, regardless of the auth method used.
+type Principal struct {
+	ID     string   `json:"id"`
+	Kind   string   `json:"kind"`
+	Name   string   `json:"name"`
+	Owner  string   `json:"owner"`
+	Tenant string   `json:"tenant,omitempty"`
+	Scopes []string `json:"scopes"`
+}
+
+// HasScope reports whether the principal has been granted the scope.
+func (p *Principal) HasScope(scope string) bool {
+	return p != nil && slices.Contains(p.Scopes, scope)
+}
+
+// WithPrincipal returns a copy of ctx carrying the principal. The principal
+// and its tenant are also attached to the logger fields of ctx.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	if principal != nil {
+		fields := []zap.Field{zap.String("principal_id", principal.ID), zap.String("principal_kind", principal.Kind)}
+		if principal.Tenant != "" {
+			fields = append(fields, zap.String("tenant", principal.Tenant))
+		}
+		ctx = applog.WithFields(ctx, fields...)
+	}
+
+	return context.WithValue(ctx, principalCtxKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal stored in ctx, if any.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalCtxKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// SetPrincipal stores the principal on the fiber context and its user context.
+func SetPrincipal(ctx *fiber.Ctx, principal *Principal) {
+	ctx.Locals(localsPrincipal, principal)
+	ctx.SetUserContext(WithPrincipal(ctx.UserContext(), principal))
+}
+
+// PrincipalFromFiber returns the principal stored on the fiber context, if any.
+func PrincipalFromFiber(ctx *fiber.Ctx) (*Principal, bool) {
+	principal, ok := ctx.Locals(localsPrincipal).(*Principal)
+	return principal, ok && principal != nil
+}
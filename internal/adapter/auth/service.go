@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	grds "github.com/redis/go-redis/v9"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"github.com/widnyana/wasabi/internal/adapter/database/pg"
+	applog "github.com/widnyana/wasabi/internal/adapter/logger"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const (
+	touchTimeout = 5 * time.Second
+
+	// createAttempts bounds the retries of Create when the random key id
+	// collides with an existing prefix.
+	createAttempts = 3
+)
+
+type (
+	// APIKeyService creates, resolves and revokes API keys.
+	APIKeyService struct {
+		cfg     Config
+		store   *Store
+		cache   keyCache
+		logger  *otelzap.Logger
+		touched sync.Map
+	}
+
+	// APIKeyServiceParams holds the dependencies of APIKeyService.
+	// Redis is optional; lookups are not cached when it is disabled.
+	APIKeyServiceParams struct {
+		fx.In
+
+		Config Config
+		Store  *Store
+		Logger *otelzap.Logger
+		Redis  *grds.Client `optional:"true"`
+	}
+
+	// CreateAPIKeyParams describes a new API key.
+	CreateAPIKeyParams struct {
+		Name      string
+		Owner     string
+		Scopes    []string
+		ExpiresAt *time.Time
+	}
+)
+
+// NewAPIKeyService creates a new APIKeyService. It fails without a pepper,
+// which would leave the stored hashes unkeyed.
+func NewAPIKeyService(params APIKeyServiceParams) (*APIKeyService, error) {
+	if params.Config.Pepper == "" {
+		return nil, ErrEmptyPepper
+	}
+
+	return &APIKeyService{
+		cfg:    params.Config,
+		store:  params.Store,
+		cache:  keyCache{client: params.Redis, ttl: params.Config.CacheTTL},
+		logger: params.Logger,
+	}, nil
+}
+
+// Create generates and stores a new API key.
+// The returned plain key is never stored and cannot be recovered later.
+// A new key is generated when its id collides with an existing one.
+func (svc *APIKeyService) Create(ctx context.Context, params CreateAPIKeyParams) (string, *APIKey, error) {
+	ctx, span := otel.Tracer("auth").Start(ctx, "apikey-create")
+	defer span.End()
+
+	for attempt := 1; ; attempt++ {
+		plain, public, err := GenerateKey(svc.cfg.KeyPrefix)
+		if err != nil {
+			return "", nil, err
+		}
+
+		key := &APIKey{
+			ID:        uuid.NewString(),
+			Name:      params.Name,
+			Owner:     params.Owner,
+			Prefix:    public,
+			Hash:      hashKey(svc.cfg.Pepper, plain),
+			Scopes:    params.Scopes,
+			ExpiresAt: params.ExpiresAt,
+			CreatedAt: time.Now().UTC(),
+		}
+
+		err = svc.store.Create(ctx, key)
+		if pg.IsUniqueViolation(err) && attempt < createAttempts {
+			applog.Ctx(svc.logger, ctx).Warn("api key id collided, retrying", zap.Int("attempt", attempt))
+			continue
+		}
+		if err != nil {
+			return "", nil, err
+		}
+
+		return plain, key, nil
+	}
+}
+
+// Authenticate resolves a plain API key into a principal.
+func (svc *APIKeyService) Authenticate(ctx context.Context, plain string) (*Principal, error) {
+	ctx, span := otel.Tracer("auth").Start(ctx, "apikey-authenticate")
+	defer span.End()
+
+	if !strings.HasPrefix(plain, svc.cfg.KeyPrefix+"_") {
+		return nil, ErrInvalidAPIKey
+	}
+
+	hash := hashKey(svc.cfg.Pepper, plain)
+	key, ok := svc.cache.get(ctx, hash)
+	if !ok {
+		var err error
+		key, err = svc.store.FindByHash(ctx, hash)
+		if errors.Is(err, ErrAPIKeyNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if err := svc.cache.set(ctx, key); err != nil {
+			applog.Ctx(svc.logger, ctx).Warn("failed to cache api key", zap.Error(err))
+		}
+	}
+
+	now := time.Now()
+	if err := key.Validate(now); err != nil {
+		return nil, err
+	}
+
+	svc.touch(ctx, key.ID, now)
+
+	return key.Principal(), nil
+}
+
+// List returns the API keys of an owner, or all keys when owner is empty.
+func (svc *APIKeyService) List(ctx context.Context, owner string) ([]APIKey, error) {
+	return svc.store.List(ctx, owner)
+}
+
+// Revoke revokes an API key and replaces it in the cache with a tombstone.
+func (svc *APIKeyService) Revoke(ctx context.Context, id string) error {
+	ctx, span := otel.Tracer("auth").Start(ctx, "apikey-revoke")
+	defer span.End()
+
+	key, err := svc.store.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := svc.store.Revoke(ctx, id, time.Now().UTC()); err != nil {
+		return err
+	}
+
+	return svc.cache.delete(ctx, key.Hash)
+}
+
+// touch updates last_used_at in the background, at most once per LastUsedInterval.
+func (svc *APIKeyService) touch(ctx context.Context, id string, now time.Time) {
+	if last, ok := svc.touched.Load(id); ok && now.Sub(last.(time.Time)) < svc.cfg.LastUsedInterval {
+		return
+	}
+	svc.touched.Store(id, now)
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), touchTimeout)
+		defer cancel()
+
+		if err := svc.store.Touch(ctx, id, now.UTC()); err != nil {
+			applog.Ctx(svc.logger, ctx).Warn("failed to update api key last use", zap.String("id", id), zap.Error(err))
+		}
+	}()
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/widnyana/wasabi/internal/adapter/database/pg"
+	"gorm.io/gorm"
+)
+
+// Store persists API keys in Postgres.
+type Store struct {
+	db *gorm.DB
+}
+
+// NewStore creates a new Store.
+func NewStore(db *gorm.DB) *Store {
+	return &Store{db: db}
+}
+
+// Create inserts a new API key.
+func (store *Store) Create(ctx context.Context, key *APIKey) error {
+	return store.db.WithContext(ctx).Create(key).Error
+}
+
+// FindByHash returns the API key with the given hash. It reads from the
+// primary, since the result is cached and a lagging replica could still
+// return a revoked key as valid.
+func (store *Store) FindByHash(ctx context.Context, hash string) (*APIKey, error) {
+	var key APIKey
+	err := store.db.WithContext(pg.WithPrimary(ctx)).Where("hash = ?", hash).Take(&key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAPIKeyNotFound
+	}
+
+	return &key, err
+}
+
+// FindByID returns the API key with the given id.
+func (store *Store) FindByID(ctx context.Context, id string) (*APIKey, error) {
+	var key APIKey
+	err := store.db.WithContext(ctx).Where("id = ?", id).Take(&key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAPIKeyNotFound
+	}
+
+	return &key, err
+}
+
+// List returns the API keys of an owner, or all keys when owner is empty.
+func (store *Store) List(ctx context.Context, owner string) ([]APIKey, error) {
+	query := store.db.WithContext(ctx).Order("created_at DESC")
+	if owner != "" {
+		query = query.Where("owner = ?", owner)
+	}
+
+	var keys []APIKey
+	return keys, query.Find(&keys).Error
+}
+
+// Revoke marks the API key as revoked. It returns ErrAPIKeyRevoked when the
+// key was already revoked.
+func (store *Store) Revoke(ctx context.Context, id string, at time.Time) error {
+	result := store.db.WithContext(ctx).
+		Model(&APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		if _, err := store.FindByID(pg.WithPrimary(ctx), id); err != nil {
+			return err
+		}
+		return ErrAPIKeyRevoked
+	}
+
+	return nil
+}
+
+// Touch records the last time the API key was used.
+func (store *Store) Touch(ctx context.Context, id string, at time.Time) error {
+	return store.db.WithContext(ctx).
+		Model(&APIKey{}).
+		Where("id = ?", id).
+		Update("last_used_at", at).Error
+}
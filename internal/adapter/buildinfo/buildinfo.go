@@ -0,0 +1,67 @@
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync"
+
+	"github.com/widnyana/wasabi/internal/constant"
+)
+
+// Info describes the running build.
+type Info struct {
+	Version        string `json:"version"`
+	ReleaseVersion string `json:"release_version,omitempty"`
+	CommitHash     string `json:"commit_hash"`
+	Branch         string `json:"branch"`
+	Buildtime      string `json:"build_time"`
+	GoVersion      string `json:"go_version"`
+	Modified       bool   `json:"modified,omitempty"`
+}
+
+var (
+	once sync.Once
+	info Info
+)
+
+// Get returns the build info. Values injected through ldflags into
+// internal/constant win; the ones left at their defaults are taken from
+// debug.ReadBuildInfo when the binary carries module and VCS information.
+func Get() Info {
+	once.Do(func() {
+		info = Info{
+			Version:        constant.AppVersion,
+			ReleaseVersion: constant.ReleaseVersion,
+			CommitHash:     constant.CommitHash,
+			Branch:         constant.Branch,
+			Buildtime:      constant.Buildtime,
+			GoVersion:      runtime.Version(),
+		}
+
+		bi, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+
+		if info.Version == constant.DefaultAppVersion && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			info.Version = bi.Main.Version
+		}
+
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.CommitHash == constant.DefaultCommitHash {
+					info.CommitHash = setting.Value
+				}
+			case "vcs.time":
+				if info.Buildtime == constant.DefaultBuildtime {
+					info.Buildtime = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	})
+
+	return info
+}
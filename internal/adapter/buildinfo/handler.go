@@ -0,0 +1,15 @@
+package buildinfo
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// VersionPath is the path of the version endpoint.
+const VersionPath = "/version"
+
+// ServeVersion serves the build info as JSON on VersionPath.
+func ServeVersion(app *fiber.App) {
+	app.Get(VersionPath, func(ctx *fiber.Ctx) error {
+		return ctx.JSON(Get())
+	})
+}
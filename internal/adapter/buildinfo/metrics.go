@@ -0,0 +1,27 @@
+package buildinfo
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RegisterMetrics registers the wasabi_build_info gauge, which is always 1
+// and carries the build info as labels.
+func RegisterMetrics(registerer prometheus.Registerer) error {
+	build := Get()
+
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "wasabi_build_info",
+		Help: "Build information of the running wasabi binary",
+		ConstLabels: prometheus.Labels{
+			"version":         build.Version,
+			"release_version": build.ReleaseVersion,
+			"commit_hash":     build.CommitHash,
+			"branch":          build.Branch,
+			"build_time":      build.Buildtime,
+			"go_version":      build.GoVersion,
+		},
+	})
+	gauge.Set(1)
+
+	return registerer.Register(gauge)
+}
@@ -0,0 +1,10 @@
+package buildinfo
+
+import "go.uber.org/fx"
+
+// Module exposes the build info as a metric and a /version endpoint.
+var Module = fx.Module(
+	"buildinfo",
+	fx.Invoke(RegisterMetrics),
+	fx.Invoke(ServeVersion),
+)
@@ -0,0 +1,19 @@
+package buildinfo
+
+import (
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+)
+
+// Resource returns the otel resource describing this service and its build.
+func Resource() *resource.Resource {
+	build := Get()
+
+	return resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName("wasabi"),
+		semconv.ServiceVersion(build.Version),
+		semconv.VCSRefHeadRevision(build.CommitHash),
+		semconv.VCSRefHeadName(build.Branch),
+	)
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"go.uber.org/fx"
+)
+
+// ErrUnknownCommand is returned when the requested command is not registered.
+var ErrUnknownCommand = errors.New("unknown command")
+
+type (
+	// Command is a named CLI command. Leaf commands set Run; group commands
+	// set Subcommands and dispatch to them by the next argument.
+	Command struct {
+		Name        string
+		Usage       string
+		Short       string
+		Run         func(ctx context.Context, args []string) error
+		Subcommands []*Command
+	}
+
+	// App dispatches arguments to the registered commands.
+	App struct {
+		commands []*Command
+		out      io.Writer
+	}
+
+	// Params holds the commands collected from the "commands" value group.
+	Params struct {
+		fx.In
+
+		Commands []*Command `group:"commands"`
+	}
+)
+
+// Module is the fx module for the command line dispatcher.
+var Module = fx.Module("cli", fx.Provide(NewApp))
+
+// AsCommand annotates a constructor so its *Command result joins the
+// "commands" value group.
+func AsCommand(constructor any) any {
+	return fx.Annotate(constructor, fx.ResultTags(`group:"commands"`))
+}
+
+// NewApp creates a new App from the registered commands.
+func NewApp(params Params) *App {
+	commands := make([]*Command, 0, len(params.Commands))
+	for _, cmd := range params.Commands {
+		if cmd != nil {
+			commands = append(commands, cmd)
+		}
+	}
+
+	sort.Slice(commands, func(i, j int) bool { return commands[i].Name < commands[j].Name })
+
+	return &App{commands: commands, out: os.Stdout}
+}
+
+// Run dispatches args (without the program name) to the matching command.
+func (app *App) Run(ctx context.Context, args []string) error {
+	return dispatch(ctx, app.out, "", app.commands, args)
+}
+
+func dispatch(ctx context.Context, out io.Writer, parent string, commands []*Command, args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(out, parent, commands)
+		return nil
+	}
+
+	name := args[0]
+	for _, cmd := range commands {
+		if cmd.Name != name {
+			continue
+		}
+
+		if len(cmd.Subcommands) > 0 {
+			return dispatch(ctx, out, strings.TrimSpace(parent+" "+name), cmd.Subcommands, args[1:])
+		}
+
+		return cmd.Run(ctx, args[1:])
+	}
+
+	printUsage(out, parent, commands)
+	return fmt.Errorf("%w: %s", ErrUnknownCommand, strings.TrimSpace(parent+" "+name))
+}
+
+func printUsage(out io.Writer, parent string, commands []*Command) {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	defer w.Flush()
+
+	_, _ = fmt.Fprintf(w, "Usage: %s <command> [arguments]\n\nCommands:\n", strings.TrimSpace("wasabi "+parent))
+	for _, cmd := range commands {
+		usage := cmd.Name
+		if cmd.Usage != "" {
+			usage = cmd.Name + " " + cmd.Usage
+		}
+		_, _ = fmt.Fprintf(w, "  %s\t%s\n", usage, cmd.Short)
+	}
+}
@@ -0,0 +1,125 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync/atomic"
+	"time"
+
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	applog "github.com/widnyana/wasabi/internal/adapter/logger"
+	"go.uber.org/zap"
+)
+
+var ErrLeaderRetry = errors.New("leader retry interval must be positive")
+
+// Leader elects a single instance among those sharing the database by
+// holding a session advisory lock on a dedicated connection. The lock is
+// released by Postgres when the connection dies, so a crashed leader is
+// replaced after at most one retry interval.
+type Leader struct {
+	db      *sql.DB
+	name    string
+	key     int64
+	retry   time.Duration
+	logger  *otelzap.Logger
+	leading atomic.Bool
+}
+
+// NewLeader creates a Leader for name, which also derives the lock key.
+// retry is how often leadership is tried for and, once held, verified; it
+// must be positive.
+func NewLeader(db *sql.DB, name string, retry time.Duration, logger *otelzap.Logger) (*Leader, error) {
+	if retry <= 0 {
+		return nil, fmt.Errorf("%w: %s: %s", ErrLeaderRetry, name, retry)
+	}
+
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte("wasabi:leader:" + name))
+
+	return &Leader{
+		db:     db,
+		name:   name,
+		key:    int64(hash.Sum64()),
+		retry:  retry,
+		logger: logger,
+	}, nil
+}
+
+// IsLeader reports whether this instance currently holds the leadership.
+func (l *Leader) IsLeader() bool { return l.leading.Load() }
+
+// Run calls fn whenever this instance becomes the leader, with a context
+// canceled when the leadership is lost, until ctx is done.
+func (l *Leader) Run(ctx context.Context, fn func(ctx context.Context)) {
+	for {
+		l.lead(ctx, fn)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(l.retry):
+		}
+	}
+}
+
+func (l *Leader) lead(ctx context.Context, fn func(ctx context.Context)) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		applog.Ctx(l.logger, ctx).Debug("leader election failed", zap.String("leader", l.name), zap.Error(err))
+		return
+	}
+	defer conn.Close()
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.key).Scan(&acquired); err != nil || !acquired {
+		return
+	}
+
+	l.leading.Store(true)
+	applog.Ctx(l.logger, ctx).Info("acquired leadership", zap.String("leader", l.name))
+
+	defer func() {
+		l.leading.Store(false)
+		applog.Ctx(l.logger, ctx).Info("released leadership", zap.String("leader", l.name))
+
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", l.key); err != nil {
+			// Discard the connection so the lock does not outlive the leadership in the pool.
+			_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+	}()
+
+	leadCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(leadCtx)
+	}()
+
+	ticker := time.NewTicker(l.retry)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			cancel()
+			<-done
+			return
+		case <-ticker.C:
+			if err := conn.PingContext(ctx); err != nil {
+				applog.Ctx(l.logger, ctx).Warn("lost leadership", zap.String("leader", l.name), zap.Error(err))
+				cancel()
+				<-done
+				return
+			}
+		}
+	}
+}
@@ -2,95 +2,135 @@ package pg
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"runtime"
 	"strings"
 	"time"
 
 	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	applog "github.com/widnyana/wasabi/internal/adapter/logger"
+	"github.com/widnyana/wasabi/internal/adapter/redact"
 	"go.uber.org/zap"
 	gormlogger "gorm.io/gorm/logger"
 )
 
 // ZapLoggerAdapter is a GORM logger that uses Zap for logging.
 type ZapLoggerAdapter struct {
-	lgr *otelzap.Logger
-	cfg gormlogger.Config
+	lgr      *otelzap.Logger
+	cfg      gormlogger.Config
+	redactor *redact.Policy
+	metrics  *QueryMetrics
 }
 
 // NewZapLoggerAdapter creates a new ZapLoggerAdapter.
-// It takes a Zap logger and a GORM logger configuration.
+// It takes a Zap logger, a GORM logger configuration, the redaction
+// policy applied to the logged SQL and its parameters, and the metrics
+// recording every traced query. metrics may be nil.
 // It returns a GORM logger interface.
-func NewZapLoggerAdapter(lgr *otelzap.Logger, cfg gormlogger.Config) gormlogger.Interface {
+func NewZapLoggerAdapter(
+	lgr *otelzap.Logger,
+	cfg gormlogger.Config,
+	redactor *redact.Policy,
+	metrics *QueryMetrics,
+) gormlogger.Interface {
 	return &ZapLoggerAdapter{
-		lgr: lgr,
-		cfg: cfg,
+		lgr:      lgr,
+		cfg:      cfg,
+		redactor: redactor,
+		metrics:  metrics,
 	}
 }
 
 // Error implements logger.Interface.
-func (z *ZapLoggerAdapter) Error(_ context.Context, msg string, args ...interface{}) {
-	if z.cfg.LogLevel >= gormlogger.Info {
-		z.lgr.Sugar().Errorf(msg, args...)
+func (z *ZapLoggerAdapter) Error(ctx context.Context, msg string, args ...interface{}) {
+	if z.cfg.LogLevel >= gormlogger.Error {
+		applog.Ctx(z.lgr, ctx).Error(fmt.Sprintf(msg, args...))
 	}
 }
 
 // Info implements logger.Interface.
-func (z *ZapLoggerAdapter) Info(_ context.Context, msg string, args ...interface{}) {
+func (z *ZapLoggerAdapter) Info(ctx context.Context, msg string, args ...interface{}) {
 	if z.cfg.LogLevel >= gormlogger.Info {
-		z.lgr.Sugar().Infof(msg, args...)
+		applog.Ctx(z.lgr, ctx).Info(fmt.Sprintf(msg, args...))
 	}
 }
 
 // Warn implements logger.Interface.
-func (z *ZapLoggerAdapter) Warn(_ context.Context, msg string, args ...interface{}) {
-	if z.cfg.LogLevel >= gormlogger.Info {
-		z.lgr.Sugar().Warnf(msg, args...)
+func (z *ZapLoggerAdapter) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if z.cfg.LogLevel >= gormlogger.Warn {
+		applog.Ctx(z.lgr, ctx).Warn(fmt.Sprintf(msg, args...))
 	}
 }
 
 // Trace implements logger.Interface.
 func (z *ZapLoggerAdapter) Trace(
-	_ context.Context,
+	ctx context.Context,
 	begin time.Time,
 	fc func() (sql string, rowsAffected int64),
 	err error,
 ) {
-	if z.cfg.LogLevel <= gormlogger.Silent {
+	elapsed := time.Since(begin)
+	if z.cfg.LogLevel <= gormlogger.Silent && z.metrics == nil {
 		return
 	}
 
-	elapsed := time.Since(begin)
 	sql, rows := fc()
-	fields := []zap.Field{
-		zap.Error(err),
+	z.metrics.Observe(sql, elapsed, z.cfg.SlowThreshold)
+
+	failed := err != nil && (!errors.Is(err, gormlogger.ErrRecordNotFound) || !z.cfg.IgnoreRecordNotFoundError)
+	slow := z.cfg.SlowThreshold > 0 && elapsed >= z.cfg.SlowThreshold
+	lgr := applog.Ctx(z.lgr, ctx)
+
+	switch {
+	case failed && z.cfg.LogLevel >= gormlogger.Error:
+		lgr.Error("query failed", z.traceFields(sql, rows, elapsed, err)...)
+	case slow && z.cfg.LogLevel >= gormlogger.Warn:
+		lgr.Warn("slow query", append(z.traceFields(sql, rows, elapsed, err),
+			zap.Duration("slow_threshold", z.cfg.SlowThreshold))...)
+	case z.cfg.LogLevel >= gormlogger.Info:
+		lgr.Info("query", z.traceFields(sql, rows, elapsed, err)...)
+	}
+}
+
+func (z *ZapLoggerAdapter) traceFields(sql string, rows int64, elapsed time.Duration, err error) []zap.Field {
+	return []zap.Field{
+		zap.Error(z.redactor.Error(err)),
 		zap.String("location", fileWithLineNum()),
 		zap.String("elapsed", elapsed.String()),
-		zap.String("sql", sql),
+		zap.String("sql", z.redactor.String(sql)),
 		zap.Int64("rows_affected", rows),
 	}
+}
 
-	switch {
-	case err != nil && z.cfg.LogLevel >= gormlogger.Error:
-		z.lgr.Error("", fields...)
-	case elapsed >= z.cfg.SlowThreshold && z.cfg.LogLevel >= gormlogger.Warn:
-		z.lgr.Warn("", fields...)
-	case z.cfg.LogLevel >= gormlogger.Info:
-		z.lgr.Info("", fields...)
+// ParamsFilter implements gorm.ParamsFilter. Parameters are left out of the
+// logged SQL when ParameterizedQueries is set, and redacted otherwise.
+func (z *ZapLoggerAdapter) ParamsFilter(_ context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	if z.cfg.ParameterizedQueries {
+		return sql, nil
 	}
+
+	return sql, z.redactor.SQLParams(sql, params)
 }
 
 // LogMode sets the log level for the logger.
-// It returns a new logger with the specified log level.
+// It returns a new logger with the specified log level, leaving z untouched
+// so that sessions can change their level without affecting the others.
 func (z *ZapLoggerAdapter) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
-	z.cfg.LogLevel = level
-	return z
+	clone := *z
+	clone.cfg.LogLevel = level
+	return &clone
 }
 
+// adapterFile is the path of this file, skipped when looking for the caller.
+var _, adapterFile, _, _ = runtime.Caller(0)
+
 func fileWithLineNum() string {
 	for i := 2; i < 15; i++ {
 		_, file, line, ok := runtime.Caller(i)
-		if ok && !strings.Contains(file, "gorm.io") && !strings.HasSuffix(file, "_test.go") {
+		if ok && file != adapterFile && file != pgxFile &&
+			!strings.Contains(file, "gorm.io") && !strings.Contains(file, "github.com/jackc/") &&
+			!strings.HasSuffix(file, "_test.go") {
 			return fmt.Sprintf("%s:%d", file, line)
 		}
 	}
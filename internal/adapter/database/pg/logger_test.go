@@ -0,0 +1,58 @@
+package pg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"github.com/widnyana/wasabi/internal/adapter/redact"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestZapLoggerAdapterRedactsSQL(t *testing.T) {
+	const (
+		password = "hunter2-correct-horse"
+		email    = "jane.doe@example.com"
+	)
+
+	policy, err := redact.NewPolicy(redact.Config{
+		Enable: true,
+		Fields: []string{"password", "token"},
+		Emails: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&buf), zap.DebugLevel)
+	adapter := NewZapLoggerAdapter(otelzap.New(zap.New(core)), gormlogger.Config{LogLevel: gormlogger.Info}, policy, nil).(*ZapLoggerAdapter)
+
+	sql := `INSERT INTO "users" ("email","password") VALUES ($1,$2)`
+	_, params := adapter.ParamsFilter(context.Background(), sql, email, password)
+	adapter.Trace(context.Background(), time.Now(), func() (string, int64) {
+		return `INSERT INTO "users" ("email","password") VALUES ('` + params[0].(string) + `','` + params[1].(string) + `')`, 1
+	}, nil)
+
+	adapter.Trace(context.Background(), time.Now(), func() (string, int64) {
+		return `UPDATE "users" SET "password" = '` + password + `' WHERE "email" = '` + email + `'`, 1
+	}, errors.New("duplicate key value (email)=("+email+")"))
+
+	output := buf.String()
+	for _, secret := range []string{password, email} {
+		if strings.Contains(output, secret) {
+			t.Errorf("output leaks %q:\n%s", secret, output)
+		}
+	}
+
+	parameterized := NewZapLoggerAdapter(otelzap.New(zap.New(core)), gormlogger.Config{ParameterizedQueries: true}, policy, nil).(*ZapLoggerAdapter)
+	if _, params := parameterized.ParamsFilter(context.Background(), sql, email, password); params != nil {
+		t.Errorf("ParamsFilter() kept parameters of a parameterized logger: %v", params)
+	}
+}
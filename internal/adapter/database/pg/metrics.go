@@ -0,0 +1,89 @@
+package pg
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	lblOperation = "operation"
+	lblTable     = "table"
+
+	operationOther = "OTHER"
+	tableNone      = "none"
+)
+
+var (
+	queryBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5}
+
+	// tablePattern finds the target table of a statement, ignoring schemas.
+	tablePattern = regexp.MustCompile(`(?i)\b(?:FROM|INTO|UPDATE|JOIN|COPY)\s+(?:ONLY\s+)?(?:"?\w+"?\.)?"?(\w+)"?`)
+
+	operations = map[string]struct{}{
+		"SELECT": {}, "INSERT": {}, "UPDATE": {}, "DELETE": {}, "WITH": {},
+		"BEGIN": {}, "COMMIT": {}, "ROLLBACK": {}, "SAVEPOINT": {}, "RELEASE": {},
+		"CREATE": {}, "ALTER": {}, "DROP": {}, "TRUNCATE": {}, "LOCK": {}, "COPY": {},
+	}
+)
+
+// QueryMetrics records the duration of the queries traced by GORM and the
+// number of queries slower than Config.SlowThresholdMS.
+type QueryMetrics struct {
+	duration *prometheus.HistogramVec
+	slow     *prometheus.CounterVec
+}
+
+// NewQueryMetrics creates a new QueryMetrics registering its metrics into registerer.
+func NewQueryMetrics(registerer prometheus.Registerer) *QueryMetrics {
+	factory := promauto.With(registerer)
+
+	return &QueryMetrics{
+		duration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "db_query_duration_seconds",
+			Help:    "Duration of database queries in seconds",
+			Buckets: queryBuckets,
+		}, []string{lblOperation, lblTable}),
+		slow: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "db_slow_queries_total",
+			Help: "Number of database queries slower than the slow query threshold",
+		}, []string{lblOperation, lblTable}),
+	}
+}
+
+// Observe records a query. A zero threshold disables slow query counting.
+func (m *QueryMetrics) Observe(sql string, elapsed, slowThreshold time.Duration) {
+	if m == nil {
+		return
+	}
+
+	operation, table := classify(sql)
+	m.duration.WithLabelValues(operation, table).Observe(elapsed.Seconds())
+
+	if slowThreshold > 0 && elapsed >= slowThreshold {
+		m.slow.WithLabelValues(operation, table).Inc()
+	}
+}
+
+// classify returns the bounded operation and table labels of a statement.
+func classify(sql string) (operation, table string) {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return operationOther, tableNone
+	}
+
+	operation = strings.ToUpper(fields[0])
+	if _, ok := operations[operation]; !ok {
+		operation = operationOther
+	}
+
+	table = tableNone
+	if match := tablePattern.FindStringSubmatch(sql); match != nil {
+		table = strings.ToLower(match[1])
+	}
+
+	return operation, table
+}
@@ -0,0 +1,121 @@
+package migrate
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/widnyana/wasabi/internal/adapter/cli"
+)
+
+// NewCommand creates the `migrate` command with its up, down, to, status
+// and create subcommands.
+func NewCommand(cfg Config, migrator *Migrator) *cli.Command {
+	return &cli.Command{
+		Name:  "migrate",
+		Short: "manage the database schema",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "up",
+				Short: "apply all pending migrations",
+				Run:   func(ctx context.Context, _ []string) error { return migrator.Up(ctx) },
+			},
+			{
+				Name:  "down",
+				Usage: "[-n 1]",
+				Short: "revert the last applied migrations",
+				Run:   func(ctx context.Context, args []string) error { return migrateDown(ctx, migrator, args) },
+			},
+			{
+				Name:  "to",
+				Usage: "<version>",
+				Short: "apply or revert migrations to reach a version",
+				Run:   func(ctx context.Context, args []string) error { return migrateTo(ctx, migrator, args) },
+			},
+			{
+				Name:  "status",
+				Short: "list migrations and whether they are applied",
+				Run:   func(ctx context.Context, _ []string) error { return migrateStatus(ctx, migrator, os.Stdout) },
+			},
+			{
+				Name:  "create",
+				Usage: "[-dir <dir>] <name>",
+				Short: "create empty up and down migration files",
+				Run:   func(_ context.Context, args []string) error { return createMigration(cfg, os.Stdout, args) },
+			},
+		},
+	}
+}
+
+func migrateDown(ctx context.Context, migrator *Migrator, args []string) error {
+	flags := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+	steps := flags.Int("n", 1, "number of migrations to revert")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	return migrator.Down(ctx, *steps)
+}
+
+func migrateTo(ctx context.Context, migrator *Migrator, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: expected exactly one version", ErrUnknownVersion)
+	}
+
+	version, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrUnknownVersion, args[0])
+	}
+
+	return migrator.To(ctx, version)
+}
+
+func migrateStatus(ctx context.Context, migrator *Migrator, out io.Writer) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT\tNOTE")
+	for _, status := range statuses {
+		appliedAt, note := "pending", ""
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+		switch {
+		case status.Missing:
+			note = "not in source"
+		case status.Modified:
+			note = "modified after apply"
+		}
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", status.Version, status.Name, appliedAt, note)
+	}
+
+	return w.Flush()
+}
+
+func createMigration(cfg Config, out io.Writer, args []string) error {
+	flags := flag.NewFlagSet("migrate create", flag.ContinueOnError)
+	dir := flags.String("dir", cfg.Dir, "directory of the migration files")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("%w: expected exactly one name", ErrInvalidMigrationName)
+	}
+
+	up, down, err := Create(*dir, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(out, "created %s\ncreated %s\n", up, down)
+	return err
+}
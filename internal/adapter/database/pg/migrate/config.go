@@ -0,0 +1,26 @@
+package migrate
+
+import "time"
+
+// Config configures the schema migrations.
+type Config struct {
+	// OnStart applies the pending migrations before the HTTP server starts serving.
+	OnStart bool   `envconfig:"on_start"`
+	Table   string `envconfig:"table" default:"schema_migrations"`
+	// LockID is the key of the advisory lock serializing migrations across
+	// instances. When zero it is derived from Table.
+	LockID      int64         `envconfig:"lock_id"`
+	LockTimeout time.Duration `envconfig:"lock_timeout" default:"1m"`
+	// Check compares the applied schema with the migrations of the binary
+	// before serving, and adds it to the health report.
+	Check bool `envconfig:"check" default:"true"`
+	// OnBehind is fail to abort the start when migrations of the binary are
+	// not applied, or not_ready to start and report the schema check down on
+	// the readiness endpoint (http ReadinessPath).
+	OnBehind string `envconfig:"on_behind" default:"fail"`
+	// AheadWindow is how many applied migrations unknown to the binary are
+	// tolerated with a warning, so older code keeps running during deploys.
+	AheadWindow int `envconfig:"ahead_window" default:"5"`
+	// Dir is where `migrate create` writes new migration files.
+	Dir string `envconfig:"dir" default:"internal/adapter/database/pg/migrations"`
+}
@@ -0,0 +1,51 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const migrationFileMode = 0o644
+
+var namePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Create writes an empty up and down migration named name into dir, with
+// the version following the highest one found there. It returns their paths.
+func Create(dir, name string) (up, down string, err error) {
+	name = strings.ToLower(strings.Join(strings.Fields(name), "_"))
+	if !namePattern.MatchString(name) {
+		return "", "", fmt.Errorf("%w: %q, use lowercase letters, digits and underscores", ErrInvalidMigrationName, name)
+	}
+
+	migrations, err := Load(Source{FS: os.DirFS(dir)})
+	if err != nil {
+		return "", "", err
+	}
+
+	next := Migration{Version: 1, Name: name}
+	if len(migrations) > 0 {
+		next.Version = migrations[len(migrations)-1].Version + 1
+	}
+
+	up = filepath.Join(dir, next.String()+".up.sql")
+	down = filepath.Join(dir, next.String()+".down.sql")
+
+	for path, direction := range map[string]string{up: "up", down: "down"} {
+		// O_EXCL guards against overwriting a migration created concurrently.
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, migrationFileMode)
+		if err != nil {
+			return "", "", err
+		}
+
+		_, err = fmt.Fprintf(file, "-- %s %s\n", next, direction)
+		if err := errors.Join(err, file.Close()); err != nil {
+			return "", "", err
+		}
+	}
+
+	return up, down, nil
+}
@@ -0,0 +1,17 @@
+package migrate
+
+import "errors"
+
+var (
+	ErrInvalidFilename      = errors.New("invalid migration filename")
+	ErrDuplicateVersion     = errors.New("duplicate migration version")
+	ErrMissingUp            = errors.New("migration has no up script")
+	ErrIrreversible         = errors.New("migration has no down script")
+	ErrChecksumMismatch     = errors.New("applied migration was modified")
+	ErrUnknownVersion       = errors.New("unknown migration version")
+	ErrLockTimeout          = errors.New("timed out waiting for the migration lock")
+	ErrInvalidMigrationName = errors.New("invalid migration name")
+	ErrSchemaBehind         = errors.New("schema is behind the binary")
+	ErrSchemaAhead          = errors.New("schema is too far ahead of the binary")
+	ErrInvalidOnBehind      = errors.New("invalid on_behind, expected fail or not_ready")
+)
@@ -0,0 +1,102 @@
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// noTransaction marks migrations that must run outside of a transaction,
+// such as CREATE INDEX CONCURRENTLY. It must be the first line of the script.
+const noTransaction = "-- migrate:no-transaction"
+
+var filenamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+type (
+	// Source is the file system holding the migration files.
+	Source struct {
+		FS fs.FS
+	}
+
+	// Migration is a versioned pair of up and down scripts.
+	Migration struct {
+		Version       int64
+		Name          string
+		Up            string
+		Down          string
+		NoTransaction bool
+		// Checksum is the SHA-256 of Up, stored when the migration is applied.
+		Checksum string
+	}
+)
+
+// Load reads the migrations of source, ordered by version.
+func Load(source Source) ([]Migration, error) {
+	entries, err := fs.ReadDir(source.FS, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		match := filenamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFilename, entry.Name())
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFilename, entry.Name())
+		}
+
+		script, err := fs.ReadFile(source.FS, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("%w: %d", ErrDuplicateVersion, version)
+		}
+
+		switch match[3] {
+		case "up":
+			migration.Up = string(script)
+			migration.NoTransaction = strings.HasPrefix(strings.TrimSpace(migration.Up), noTransaction)
+			sum := sha256.Sum256(script)
+			migration.Checksum = hex.EncodeToString(sum[:])
+		case "down":
+			migration.Down = string(script)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("%w: %s", ErrMissingUp, migration)
+		}
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// String returns the file name prefix of the migration.
+func (m Migration) String() string {
+	return fmt.Sprintf("%06d_%s", m.Version, m.Name)
+}
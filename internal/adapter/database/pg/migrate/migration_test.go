@@ -0,0 +1,109 @@
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		want    []string
+		noTx    []bool
+		wantErr error
+	}{
+		{
+			name: "ordered by version",
+			files: fstest.MapFS{
+				"000002_add_owner.up.sql":     {Data: []byte("ALTER TABLE t ADD owner text;")},
+				"000002_add_owner.down.sql":   {Data: []byte("ALTER TABLE t DROP owner;")},
+				"000001_create_t.up.sql":      {Data: []byte("CREATE TABLE t ();")},
+				"000010_index_owner.up.sql":   {Data: []byte("-- migrate:no-transaction\nCREATE INDEX CONCURRENTLY t_owner ON t (owner);")},
+				"README.md":                   {Data: []byte("not a migration")},
+				"000001_create_t.down.sql":    {Data: []byte("DROP TABLE t;")},
+				"000010_index_owner.down.sql": {Data: []byte("DROP INDEX t_owner;")},
+			},
+			want: []string{"000001_create_t", "000002_add_owner", "000010_index_owner"},
+			noTx: []bool{false, false, true},
+		},
+		{
+			name: "no-transaction must be the first line",
+			files: fstest.MapFS{
+				"000001_index.up.sql": {Data: []byte("CREATE INDEX i ON t (c);\n-- migrate:no-transaction")},
+			},
+			want: []string{"000001_index"},
+			noTx: []bool{false},
+		},
+		{
+			name: "invalid filename",
+			files: fstest.MapFS{
+				"000001_Create-T.up.sql": {Data: []byte("CREATE TABLE t ();")},
+			},
+			wantErr: ErrInvalidFilename,
+		},
+		{
+			name: "missing direction",
+			files: fstest.MapFS{
+				"000001_create_t.sql": {Data: []byte("CREATE TABLE t ();")},
+			},
+			wantErr: ErrInvalidFilename,
+		},
+		{
+			name: "duplicate version",
+			files: fstest.MapFS{
+				"000001_create_t.up.sql": {Data: []byte("CREATE TABLE t ();")},
+				"000001_create_u.up.sql": {Data: []byte("CREATE TABLE u ();")},
+			},
+			wantErr: ErrDuplicateVersion,
+		},
+		{
+			name: "down without up",
+			files: fstest.MapFS{
+				"000001_create_t.down.sql": {Data: []byte("DROP TABLE t;")},
+			},
+			wantErr: ErrMissingUp,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := Load(Source{FS: tt.files})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Load() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if len(migrations) != len(tt.want) {
+				t.Fatalf("Load() returned %v, want %v", migrations, tt.want)
+			}
+			for i, migration := range migrations {
+				if migration.String() != tt.want[i] || migration.NoTransaction != tt.noTx[i] {
+					t.Errorf("migration %d = %s (no-transaction %t), want %s (%t)",
+						i, migration, migration.NoTransaction, tt.want[i], tt.noTx[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLoadChecksum(t *testing.T) {
+	up := []byte("CREATE TABLE t ();")
+	migrations, err := Load(Source{FS: fstest.MapFS{
+		"000001_create_t.up.sql":   {Data: up},
+		"000001_create_t.down.sql": {Data: []byte("DROP TABLE t;")},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sum := sha256.Sum256(up)
+	if want := hex.EncodeToString(sum[:]); migrations[0].Checksum != want {
+		t.Errorf("Checksum = %s, want the SHA-256 of the up script %s", migrations[0].Checksum, want)
+	}
+}
@@ -0,0 +1,371 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	applog "github.com/widnyana/wasabi/internal/adapter/logger"
+	"go.uber.org/zap"
+)
+
+type (
+	// Migrator applies and reverts migrations through *sql.DB. Every operation
+	// holds a Postgres advisory lock so concurrent instances never race.
+	Migrator struct {
+		db         *sql.DB
+		cfg        Config
+		migrations []Migration
+		logger     *otelzap.Logger
+	}
+
+	// Applied is a row of the migrations table.
+	Applied struct {
+		Version   int64
+		Name      string
+		Checksum  string
+		AppliedAt time.Time
+	}
+
+	// Status describes a migration known by the source or the database.
+	Status struct {
+		Version   int64
+		Name      string
+		Applied   bool
+		AppliedAt *time.Time
+		// Modified reports an applied migration whose script changed since.
+		Modified bool
+		// Missing reports an applied migration unknown to the source.
+		Missing bool
+	}
+)
+
+// NewMigrator creates a Migrator for the migrations of source.
+func NewMigrator(db *sql.DB, cfg Config, source Source, logger *otelzap.Logger, levels *applog.Levels) (*Migrator, error) {
+	migrations, err := Load(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         db,
+		cfg:        cfg,
+		migrations: migrations,
+		logger:     levels.Named(logger, "migrate"),
+	}, nil
+}
+
+// Migrations returns the migrations of the source, ordered by version.
+func (m *Migrator) Migrations() []Migration { return m.migrations }
+
+// Latest returns the highest version of the source, 0 when it is empty.
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up applies every pending migration.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.Latest())
+}
+
+// Down reverts the last steps applied migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.withLock(ctx, func(conn *sql.Conn, applied []Applied) error {
+		for i := len(applied) - 1; i >= 0 && steps > 0; i, steps = i-1, steps-1 {
+			if err := m.revert(ctx, conn, applied[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// To migrates the schema to version: pending migrations up to version are
+// applied, applied migrations above it are reverted.
+func (m *Migrator) To(ctx context.Context, version int64) error {
+	return m.withLock(ctx, func(conn *sql.Conn, applied []Applied) error {
+		if err := m.verify(applied); err != nil {
+			return err
+		}
+
+		for i := len(applied) - 1; i >= 0 && applied[i].Version > version; i-- {
+			if err := m.revert(ctx, conn, applied[i]); err != nil {
+				return err
+			}
+		}
+
+		done := make(map[int64]bool, len(applied))
+		for _, row := range applied {
+			done[row.Version] = true
+		}
+
+		for _, migration := range m.migrations {
+			if migration.Version > version || done[migration.Version] {
+				continue
+			}
+			if err := m.apply(ctx, conn, migration); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Status reports the state of every migration of the source and the database.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	if err := m.ensureTable(ctx, m.db); err != nil {
+		return nil, err
+	}
+
+	applied, err := m.applied(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]Applied, len(applied))
+	for _, row := range applied {
+		byVersion[row.Version] = row
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if row, ok := byVersion[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = &row.AppliedAt
+			status.Modified = row.Checksum != migration.Checksum
+			delete(byVersion, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+
+	for _, row := range byVersion {
+		statuses = append(statuses, Status{
+			Version: row.Version, Name: row.Name, Applied: true, AppliedAt: &row.AppliedAt, Missing: true,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+
+	return statuses, nil
+}
+
+// Current returns the highest applied version, 0 when none is applied.
+func (m *Migrator) Current(ctx context.Context) (int64, error) {
+	if err := m.ensureTable(ctx, m.db); err != nil {
+		return 0, err
+	}
+
+	var version sql.NullInt64
+	err := m.db.QueryRowContext(ctx, "SELECT MAX(version) FROM "+m.table()).Scan(&version)
+	if err != nil {
+		return 0, err
+	}
+
+	return version.Int64, nil
+}
+
+// verify checks that applied migrations were not edited afterwards.
+func (m *Migrator) verify(applied []Applied) error {
+	known := make(map[int64]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = migration
+	}
+
+	for _, row := range applied {
+		migration, ok := known[row.Version]
+		if ok && migration.Checksum != row.Checksum {
+			return fmt.Errorf("%w: %s", ErrChecksumMismatch, migration)
+		}
+	}
+
+	return nil
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	started := time.Now()
+	err := m.run(ctx, conn, migration.NoTransaction, migration.Up, func(exec execer) error {
+		_, err := exec.ExecContext(ctx,
+			"INSERT INTO "+m.table()+" (version, name, checksum) VALUES ($1, $2, $3)",
+			migration.Version, migration.Name, migration.Checksum,
+		)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("apply %s: %w", migration, err)
+	}
+
+	applog.Ctx(m.logger, ctx).Info("migration applied",
+		zap.Int64("version", migration.Version),
+		zap.String("name", migration.Name),
+		zap.Duration("elapsed", time.Since(started)),
+	)
+
+	return nil
+}
+
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, row Applied) error {
+	var migration *Migration
+	for i := range m.migrations {
+		if m.migrations[i].Version == row.Version {
+			migration = &m.migrations[i]
+			break
+		}
+	}
+
+	if migration == nil {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, row.Version)
+	}
+	if strings.TrimSpace(migration.Down) == "" {
+		return fmt.Errorf("%w: %s", ErrIrreversible, migration)
+	}
+
+	started := time.Now()
+	noTx := strings.HasPrefix(strings.TrimSpace(migration.Down), noTransaction)
+	err := m.run(ctx, conn, noTx, migration.Down, func(exec execer) error {
+		_, err := exec.ExecContext(ctx, "DELETE FROM "+m.table()+" WHERE version = $1", migration.Version)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("revert %s: %w", migration, err)
+	}
+
+	applog.Ctx(m.logger, ctx).Info("migration reverted",
+		zap.Int64("version", migration.Version),
+		zap.String("name", migration.Name),
+		zap.Duration("elapsed", time.Since(started)),
+	)
+
+	return nil
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// run executes script and record in one transaction, unless noTx is set.
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, noTx bool, script string, record func(execer) error) error {
+	if noTx {
+		if _, err := conn.ExecContext(ctx, script); err != nil {
+			return err
+		}
+		return record(conn)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	if err := record(tx); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	return tx.Commit()
+}
+
+// withLock runs fn on a dedicated connection holding the advisory lock,
+// with the applied migrations read under the lock.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn, applied []Applied) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	lockCtx := ctx
+	if m.cfg.LockTimeout > 0 {
+		var cancel context.CancelFunc
+		lockCtx, cancel = context.WithTimeout(ctx, m.cfg.LockTimeout)
+		defer cancel()
+	}
+
+	if _, err := conn.ExecContext(lockCtx, "SELECT pg_advisory_lock($1)", m.lockID()); err != nil {
+		if errors.Is(lockCtx.Err(), context.DeadlineExceeded) {
+			return ErrLockTimeout
+		}
+		return err
+	}
+
+	defer func() {
+		// The lock is released with the session anyway if this fails.
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", m.lockID()); err != nil {
+			applog.Ctx(m.logger, ctx).Warn("failed to release the migration lock", zap.Error(err))
+		}
+	}()
+
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return err
+	}
+
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	return fn(conn, applied)
+}
+
+type querier interface {
+	execer
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func (m *Migrator) ensureTable(ctx context.Context, db execer) error {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+m.table()+` (
+		version    BIGINT PRIMARY KEY,
+		name       TEXT        NOT NULL,
+		checksum   TEXT        NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`)
+	return err
+}
+
+// applied returns the applied migrations ordered by version.
+func (m *Migrator) applied(ctx context.Context, db querier) ([]Applied, error) {
+	rows, err := db.QueryContext(ctx, "SELECT version, name, checksum, applied_at FROM "+m.table()+" ORDER BY version")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var applied []Applied
+	for rows.Next() {
+		var row Applied
+		if err := rows.Scan(&row.Version, &row.Name, &row.Checksum, &row.AppliedAt); err != nil {
+			return nil, err
+		}
+		applied = append(applied, row)
+	}
+
+	return applied, rows.Err()
+}
+
+func (m *Migrator) table() string {
+	return `"` + strings.ReplaceAll(m.cfg.Table, `"`, `""`) + `"`
+}
+
+func (m *Migrator) lockID() int64 {
+	if m.cfg.LockID != 0 {
+		return m.cfg.LockID
+	}
+
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte("wasabi:migrate:" + m.cfg.Table))
+	return int64(hash.Sum64())
+}
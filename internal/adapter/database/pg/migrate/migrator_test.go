@@ -0,0 +1,44 @@
+package migrate
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMigratorVerify(t *testing.T) {
+	migrator := &Migrator{migrations: []Migration{
+		{Version: 1, Name: "create_t", Checksum: "aaa"},
+		{Version: 2, Name: "add_owner", Checksum: "bbb"},
+	}}
+
+	tests := []struct {
+		name    string
+		applied []Applied
+		wantErr error
+	}{
+		{
+			name: "nothing applied",
+		},
+		{
+			name:    "matching checksums",
+			applied: []Applied{{Version: 1, Checksum: "aaa"}, {Version: 2, Checksum: "bbb"}},
+		},
+		{
+			name:    "modified migration",
+			applied: []Applied{{Version: 1, Checksum: "aaa"}, {Version: 2, Checksum: "ccc"}},
+			wantErr: ErrChecksumMismatch,
+		},
+		{
+			name:    "version unknown to the binary",
+			applied: []Applied{{Version: 3, Checksum: "ddd"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := migrator.verify(tt.applied); !errors.Is(err, tt.wantErr) {
+				t.Errorf("verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
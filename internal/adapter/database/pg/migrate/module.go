@@ -0,0 +1,37 @@
+package migrate
+
+import (
+	"context"
+
+	"github.com/widnyana/wasabi/internal/adapter/cli"
+	"github.com/widnyana/wasabi/internal/adapter/database/pg/migrations"
+	"github.com/widnyana/wasabi/internal/adapter/http"
+	"go.uber.org/fx"
+)
+
+var (
+	Module = fx.Module("migrate", Providers)
+
+	Providers = fx.Options(
+		fx.Provide(func() Source { return Source{FS: migrations.FS} }),
+		fx.Provide(NewMigrator),
+		fx.Provide(cli.AsCommand(NewCommand)),
+		fx.Provide(http.AsStartGate(NewStartGate)),
+		fx.Provide(NewSchemaChecker),
+		fx.Provide(fx.Annotate(NewSchemaChecks, fx.ResultTags(`group:"health_checks,flatten"`))),
+		fx.Provide(http.AsStartGate(NewSchemaGate)),
+	)
+)
+
+// NewStartGate applies the pending migrations before the HTTP server starts
+// serving when OnStart is enabled.
+func NewStartGate(cfg Config, migrator *Migrator) http.StartGate {
+	if !cfg.OnStart {
+		return http.StartGate{}
+	}
+
+	return http.StartGate{
+		Name: "migrate",
+		Run:  func(ctx context.Context) error { return migrator.Up(ctx) },
+	}
+}
@@ -0,0 +1,159 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"github.com/widnyana/wasabi/internal/adapter/health"
+	"github.com/widnyana/wasabi/internal/adapter/http"
+	applog "github.com/widnyana/wasabi/internal/adapter/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// OnBehindFail aborts the start when the schema is behind.
+	OnBehindFail = "fail"
+	// OnBehindNotReady starts anyway and reports the schema check down, which
+	// fails the readiness endpoint of the HTTP server.
+	OnBehindNotReady = "not_ready"
+)
+
+type (
+	// SchemaState compares the applied migrations with the ones of the binary.
+	SchemaState struct {
+		// Expected is the latest version known by the binary.
+		Expected int64
+		// Current is the highest applied version.
+		Current int64
+		// Pending are the versions of the binary not applied yet.
+		Pending []int64
+		// Unknown are the applied versions the binary does not know, usually
+		// applied by a newer release.
+		Unknown []int64
+	}
+
+	// SchemaChecker checks that the schema matches the binary. It is part of
+	// the health report and gates the start of the HTTP server.
+	SchemaChecker struct {
+		migrator *Migrator
+		cfg      Config
+		logger   *otelzap.Logger
+		// warned remembers the last Current warned about, to log once per change.
+		warned atomic.Int64
+	}
+)
+
+// Behind reports whether migrations of the binary are not applied.
+func (state SchemaState) Behind() bool { return len(state.Pending) > 0 }
+
+// Ahead returns the number of applied migrations unknown to the binary.
+func (state SchemaState) Ahead() int { return len(state.Unknown) }
+
+// NewSchemaChecker creates a new SchemaChecker.
+func NewSchemaChecker(migrator *Migrator, cfg Config) (*SchemaChecker, error) {
+	if cfg.OnBehind != OnBehindFail && cfg.OnBehind != OnBehindNotReady {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidOnBehind, cfg.OnBehind)
+	}
+
+	return &SchemaChecker{migrator: migrator, cfg: cfg, logger: migrator.logger}, nil
+}
+
+// State reads the applied migrations and compares them with the source.
+func (m *Migrator) State(ctx context.Context) (SchemaState, error) {
+	state := SchemaState{Expected: m.Latest()}
+
+	var exists bool
+	if err := m.db.QueryRowContext(ctx, "SELECT to_regclass($1) IS NOT NULL", m.table()).Scan(&exists); err != nil {
+		return state, err
+	}
+
+	applied := map[int64]bool{}
+	if exists {
+		rows, err := m.applied(ctx, m.db)
+		if err != nil {
+			return state, err
+		}
+		for _, row := range rows {
+			applied[row.Version] = true
+			state.Current = max(state.Current, row.Version)
+		}
+	}
+
+	for _, migration := range m.migrations {
+		if !applied[migration.Version] {
+			state.Pending = append(state.Pending, migration.Version)
+		}
+		delete(applied, migration.Version)
+	}
+
+	for version := range applied {
+		state.Unknown = append(state.Unknown, version)
+	}
+
+	return state, nil
+}
+
+// CheckHealth implements health.Checker. The schema is down when it is
+// behind, or ahead by more than AheadWindow migrations.
+func (checker *SchemaChecker) CheckHealth(ctx context.Context) error {
+	state, err := checker.migrator.State(ctx)
+	if err != nil {
+		return err
+	}
+
+	return checker.evaluate(ctx, state)
+}
+
+// Gate fails when the schema is not usable and OnBehind is "fail".
+func (checker *SchemaChecker) Gate(ctx context.Context) error {
+	err := checker.CheckHealth(ctx)
+	if err == nil || checker.cfg.OnBehind == OnBehindNotReady {
+		if err != nil {
+			applog.Ctx(checker.logger, ctx).Warn("schema does not match, reporting not ready", zap.Error(err))
+		}
+		return nil
+	}
+
+	return err
+}
+
+func (checker *SchemaChecker) evaluate(ctx context.Context, state SchemaState) error {
+	switch {
+	case state.Behind():
+		return fmt.Errorf("%w: applied %d, expected %d, pending %v",
+			ErrSchemaBehind, state.Current, state.Expected, state.Pending)
+	case state.Ahead() > checker.cfg.AheadWindow:
+		return fmt.Errorf("%w: %d unknown migrations %v, allowed %d",
+			ErrSchemaAhead, state.Ahead(), state.Unknown, checker.cfg.AheadWindow)
+	case state.Ahead() > 0 && checker.warned.Swap(state.Current) != state.Current:
+		applog.Ctx(checker.logger, ctx).Warn("schema is ahead of the binary",
+			zap.Int64("applied", state.Current),
+			zap.Int64("expected", state.Expected),
+			zap.Int64s("unknown", state.Unknown),
+		)
+	}
+
+	return nil
+}
+
+// NewSchemaChecks contributes the schema check to the health report when
+// Check is enabled.
+func NewSchemaChecks(cfg Config, checker *SchemaChecker) []health.Check {
+	if !cfg.Check {
+		return nil
+	}
+
+	return []health.Check{{Name: "schema", Checker: checker}}
+}
+
+// NewSchemaGate checks the schema before the HTTP server starts serving.
+// It runs after the "migrate" gate.
+func NewSchemaGate(cfg Config, checker *SchemaChecker) http.StartGate {
+	if !cfg.Check {
+		return http.StartGate{}
+	}
+
+	return http.StartGate{Name: "schema", Run: checker.Gate}
+}
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           UUID PRIMARY KEY,
+    name         TEXT        NOT NULL,
+    owner        TEXT        NOT NULL,
+    prefix       TEXT        NOT NULL,
+    hash         TEXT        NOT NULL,
+    scopes       TEXT        NOT NULL DEFAULT '',
+    expires_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_owner ON api_keys (owner);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_prefix ON api_keys (prefix);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_hash ON api_keys (hash);
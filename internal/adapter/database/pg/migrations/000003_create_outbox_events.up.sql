@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id              BIGSERIAL PRIMARY KEY,
+    aggregate_type  TEXT        NOT NULL,
+    aggregate_id    TEXT        NOT NULL,
+    type            TEXT        NOT NULL,
+    payload         JSONB       NOT NULL,
+    headers         JSONB,
+    created_at      TIMESTAMPTZ NOT NULL,
+    attempts        INTEGER     NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    last_error      TEXT,
+    published_at    TIMESTAMPTZ,
+    dead_at         TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending ON outbox_events (id)
+    WHERE published_at IS NULL AND dead_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_aggregate ON outbox_events (aggregate_type, aggregate_id, id)
+    WHERE published_at IS NULL AND dead_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_published_at ON outbox_events (published_at)
+    WHERE published_at IS NOT NULL;
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS jobs_notify ON jobs;
+DROP FUNCTION IF EXISTS notify_jobs();
+DROP TABLE IF EXISTS jobs;
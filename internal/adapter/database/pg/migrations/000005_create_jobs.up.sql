@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS jobs (
+    id           BIGSERIAL PRIMARY KEY,
+    kind         TEXT        NOT NULL,
+    payload      JSONB       NOT NULL,
+    priority     INTEGER     NOT NULL DEFAULT 0,
+    state        TEXT        NOT NULL DEFAULT 'available',
+    attempts     INTEGER     NOT NULL DEFAULT 0,
+    max_attempts INTEGER     NOT NULL,
+    unique_key   TEXT,
+    run_at       TIMESTAMPTZ NOT NULL,
+    locked_until TIMESTAMPTZ,
+    locked_by    TEXT,
+    last_error   TEXT,
+    created_at   TIMESTAMPTZ NOT NULL,
+    updated_at   TIMESTAMPTZ NOT NULL,
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_available ON jobs (priority DESC, run_at, id)
+    WHERE state = 'available';
+CREATE INDEX IF NOT EXISTS idx_jobs_running_locked_until ON jobs (locked_until)
+    WHERE state = 'running';
+CREATE INDEX IF NOT EXISTS idx_jobs_completed_at ON jobs (completed_at)
+    WHERE state = 'completed';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_unique_key ON jobs (unique_key)
+    WHERE state IN ('available', 'running');
+
+CREATE OR REPLACE FUNCTION notify_jobs() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('jobs', '');
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS jobs_notify ON jobs;
+CREATE TRIGGER jobs_notify
+    AFTER INSERT ON jobs
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_jobs();
@@ -0,0 +1,10 @@
+// Package migrations holds the SQL migrations of the application, named
+// <version>_<name>.up.sql and <version>_<name>.down.sql.
+package migrations
+
+import "embed"
+
+// FS contains the embedded migration files.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -1,6 +1,7 @@
 package pg
 
 import (
+	"github.com/widnyana/wasabi/internal/adapter/health"
 	"go.uber.org/fx"
 )
 
@@ -8,14 +9,28 @@ var (
 	Module = fx.Module("postgres", Providers, Invokers)
 
 	Providers = fx.Options(
+		fx.Provide(NewQueryMetrics),
+		fx.Provide(NewPoolCollector),
 		fx.Provide(NewGorm),
 		fx.Provide(NewSQLDB),
 		fx.Provide(NewHealthChecker),
+		fx.Provide(health.AsCheck(func(checker HealthChecker) health.Check {
+			return health.Check{Name: "postgres", Checker: checker}
+		})),
+		fx.Provide(NewTxManager),
+		fx.Provide(NewPgxPool),
+		fx.Provide(fx.Annotate(NewPgxChecks, fx.ResultTags(`group:"health_checks,flatten"`))),
+		fx.Provide(NewReplicas),
+		fx.Provide(fx.Annotate(NewReplicaChecks, fx.ResultTags(`group:"health_checks,flatten"`))),
 	)
 
 	Invokers = fx.Options(
 		fx.Invoke(EnableTracing),
 		fx.Invoke(HookConnection),
 		fx.Invoke(configureConnPool),
+		fx.Invoke(EnableReplicas),
+		fx.Invoke(HookReplicas),
+		fx.Invoke(HookPgxPool),
+		fx.Invoke(RegisterPoolMetrics),
 	)
 )
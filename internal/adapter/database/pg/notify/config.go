@@ -0,0 +1,14 @@
+package notify
+
+import "time"
+
+// Config configures the LISTEN/NOTIFY listener.
+type Config struct {
+	// Enable runs the listener on a dedicated connection to Postgres.DSN.
+	Enable              bool          `envconfig:"enable"`
+	ReconnectBackoff    time.Duration `envconfig:"reconnect_backoff" default:"1s"`
+	MaxReconnectBackoff time.Duration `envconfig:"max_reconnect_backoff" default:"30s"`
+	// PingInterval is how long the listener waits for a notification before
+	// pinging the connection to detect a silent disconnect.
+	PingInterval time.Duration `envconfig:"ping_interval" default:"30s"`
+}
@@ -0,0 +1,8 @@
+package notify
+
+import "errors"
+
+var (
+	ErrNotConnected = errors.New("notification listener is not connected")
+	ErrNoChannel    = errors.New("notification handler has no channel")
+)
@@ -0,0 +1,43 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+
+	"go.uber.org/fx"
+)
+
+type (
+	// Handler handles the notifications sent on Channel.
+	Handler struct {
+		Channel string
+		Handle  func(ctx context.Context, payload string) error
+	}
+
+	// HandlerParams holds the handlers collected from the "notify_handlers" value group.
+	HandlerParams struct {
+		fx.In
+
+		Handlers []Handler `group:"notify_handlers"`
+	}
+)
+
+// AsHandler annotates a constructor returning a Handler so it joins the
+// "notify_handlers" value group.
+func AsHandler(constructor any) any {
+	return fx.Annotate(constructor, fx.ResultTags(`group:"notify_handlers"`))
+}
+
+// HandleJSON returns a Handler decoding the JSON payloads of channel into T.
+func HandleJSON[T any](channel string, handle func(ctx context.Context, payload T) error) Handler {
+	return Handler{
+		Channel: channel,
+		Handle: func(ctx context.Context, payload string) error {
+			var value T
+			if err := json.Unmarshal([]byte(payload), &value); err != nil {
+				return err
+			}
+			return handle(ctx, value)
+		},
+	}
+}
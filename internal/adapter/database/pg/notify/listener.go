@@ -0,0 +1,174 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"github.com/widnyana/wasabi/internal/adapter/database/pg"
+	applog "github.com/widnyana/wasabi/internal/adapter/logger"
+	"go.uber.org/zap"
+)
+
+const lblChannel = "channel"
+
+// Listener subscribes to the channels of the registered handlers on a
+// dedicated connection, outside of the GORM pool, and dispatches every
+// notification to the handlers of its channel. It reconnects and
+// resubscribes with backoff when the connection is lost.
+type Listener struct {
+	cfg       Config
+	dsn       string
+	handlers  map[string][]Handler
+	channels  []string
+	connected atomic.Bool
+	logger    *otelzap.Logger
+
+	received  *prometheus.CounterVec
+	failures  *prometheus.CounterVec
+	reconnect prometheus.Counter
+}
+
+// NewListener creates a new Listener for the handlers of params.
+func NewListener(
+	cfg Config,
+	pgConfig pg.Config,
+	params HandlerParams,
+	registerer prometheus.Registerer,
+	logger *otelzap.Logger,
+) (*Listener, error) {
+	handlers := map[string][]Handler{}
+	var channels []string
+	for _, handler := range params.Handlers {
+		if handler.Channel == "" {
+			return nil, ErrNoChannel
+		}
+		if _, ok := handlers[handler.Channel]; !ok {
+			channels = append(channels, handler.Channel)
+		}
+		handlers[handler.Channel] = append(handlers[handler.Channel], handler)
+	}
+
+	factory := promauto.With(registerer)
+
+	return &Listener{
+		cfg:      cfg,
+		dsn:      pgConfig.DSN,
+		handlers: handlers,
+		channels: channels,
+		logger:   logger,
+		received: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "db_notifications_received_total",
+			Help: "Number of Postgres notifications received",
+		}, []string{lblChannel}),
+		failures: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "db_notification_handler_failures_total",
+			Help: "Number of Postgres notifications whose handler failed",
+		}, []string{lblChannel}),
+		reconnect: factory.NewCounter(prometheus.CounterOpts{
+			Name: "db_notification_listener_reconnects_total",
+			Help: "Number of times the notification listener reconnected",
+		}),
+	}, nil
+}
+
+// Channels returns the channels listened to.
+func (l *Listener) Channels() []string { return l.channels }
+
+// CheckHealth implements health.Checker.
+func (l *Listener) CheckHealth(_ context.Context) error {
+	if !l.connected.Load() {
+		return ErrNotConnected
+	}
+
+	return nil
+}
+
+// Run listens until ctx is done, reconnecting whenever the connection fails.
+func (l *Listener) Run(ctx context.Context) {
+	backoff := l.cfg.ReconnectBackoff
+
+	for {
+		subscribed, err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		// A connection that got as far as subscribing resets the backoff.
+		if subscribed {
+			backoff = l.cfg.ReconnectBackoff
+		}
+
+		l.reconnect.Inc()
+		applog.Ctx(l.logger, ctx).Warn("notification listener disconnected",
+			zap.Error(err), zap.Duration("backoff", backoff))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, l.cfg.MaxReconnectBackoff)
+	}
+}
+
+// listen subscribes on a new connection and dispatches notifications until
+// the connection fails. It reports whether the subscription succeeded.
+func (l *Listener) listen(ctx context.Context) (bool, error) {
+	conn, err := pgx.Connect(ctx, l.dsn)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		l.connected.Store(false)
+		_ = conn.Close(context.WithoutCancel(ctx))
+	}()
+
+	for _, channel := range l.channels {
+		if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+			return false, fmt.Errorf("listen %s: %w", channel, err)
+		}
+	}
+
+	l.connected.Store(true)
+	applog.Ctx(l.logger, ctx).Info("notification listener connected", zap.Strings("channels", l.channels))
+
+	for {
+		waitCtx, cancel := context.WithTimeout(ctx, l.cfg.PingInterval)
+		notification, err := conn.WaitForNotification(waitCtx)
+		cancel()
+
+		switch {
+		case err == nil:
+			l.dispatch(ctx, notification)
+		case ctx.Err() != nil:
+			return true, ctx.Err()
+		case errors.Is(err, context.DeadlineExceeded):
+			if err := conn.Ping(ctx); err != nil {
+				return true, err
+			}
+		default:
+			return true, err
+		}
+	}
+}
+
+func (l *Listener) dispatch(ctx context.Context, notification *pgconn.Notification) {
+	l.received.WithLabelValues(notification.Channel).Inc()
+
+	for _, handler := range l.handlers[notification.Channel] {
+		if err := handler.Handle(ctx, notification.Payload); err != nil {
+			l.failures.WithLabelValues(notification.Channel).Inc()
+			applog.Ctx(l.logger, ctx).Error("notification handler failed",
+				zap.String("channel", notification.Channel), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/widnyana/wasabi/internal/adapter/health"
+	"go.uber.org/fx"
+)
+
+var (
+	Module = fx.Module("notify", Providers, Invokers)
+
+	Providers = fx.Options(
+		fx.Provide(NewListener),
+		fx.Provide(fx.Annotate(NewChecks, fx.ResultTags(`group:"health_checks,flatten"`))),
+	)
+
+	Invokers = fx.Options(
+		fx.Invoke(HookListener),
+	)
+)
+
+// NewChecks contributes the listener health check when it runs.
+func NewChecks(cfg Config, listener *Listener) []health.Check {
+	if !cfg.Enable || len(listener.Channels()) == 0 {
+		return nil
+	}
+
+	return []health.Check{{Name: "postgres_listener", Checker: listener}}
+}
+
+// HookListener runs the listener in the background when enabled and some
+// handler is registered. It stops with the application context, or on stop
+// at the latest.
+func HookListener(lifecycle fx.Lifecycle, appCtx context.Context, cfg Config, listener *Listener) {
+	if !cfg.Enable || len(listener.Channels()) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(appCtx)
+	done := make(chan struct{})
+
+	lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				listener.Run(ctx)
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			<-done
+			return nil
+		},
+	})
+}
@@ -0,0 +1,28 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/widnyana/wasabi/internal/adapter/database/pg"
+	"gorm.io/gorm"
+)
+
+// Notify sends payload on channel through db. Inside a transaction the
+// notification is delivered when the transaction commits, and dropped when
+// it rolls back. The statement is a SELECT, so it is pinned to the primary:
+// a hot standby can't execute NOTIFY.
+func Notify(ctx context.Context, db *gorm.DB, channel, payload string) error {
+	return db.WithContext(pg.WithPrimary(ctx)).Exec("SELECT pg_notify(?, ?)", channel, payload).Error
+}
+
+// NotifyJSON sends value encoded as JSON on channel through db. Postgres
+// limits payloads to 8000 bytes, so send identifiers rather than documents.
+func NotifyJSON(ctx context.Context, db *gorm.DB, channel string, value any) error {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return Notify(ctx, db, channel, string(payload))
+}
@@ -0,0 +1,260 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"github.com/widnyana/wasabi/internal/adapter/health"
+	applog "github.com/widnyana/wasabi/internal/adapter/logger"
+	"github.com/widnyana/wasabi/internal/adapter/redact"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+type (
+	// PgxConfig configures the optional native pgx pool. The pool shares the
+	// DSN and the connection limits of Config with the GORM connection.
+	PgxConfig struct {
+		Enable            bool          `envconfig:"enable"`
+		MinConns          int32         `envconfig:"min_conns"`
+		HealthCheckPeriod time.Duration `envconfig:"health_check_period" default:"1m"`
+	}
+
+	// Querier is implemented by *pgxpool.Pool, *pgx.Conn and pgx.Tx.
+	Querier interface {
+		Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+		Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+		QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+		SendBatch(ctx context.Context, batch *pgx.Batch) pgx.BatchResults
+		CopyFrom(ctx context.Context, table pgx.Identifier, columns []string, src pgx.CopyFromSource) (int64, error)
+	}
+
+	// BatchQuery is a statement queued by ExecBatch.
+	BatchQuery struct {
+		SQL  string
+		Args []any
+	}
+
+	// pgxTracer traces pgx queries, batches and copies with a span each, and
+	// logs and measures them through the GORM logger adapter.
+	pgxTracer struct {
+		tracer trace.Tracer
+		logger gormlogger.Interface
+	}
+
+	pgxTraceKey struct{}
+
+	pgxTrace struct {
+		begin   time.Time
+		span    trace.Span
+		sql     string
+		queries []string
+		rows    int64
+	}
+)
+
+// pgxFile is the path of this file, skipped when looking for the caller.
+var _, pgxFile, _, _ = runtime.Caller(0)
+
+var (
+	_ pgx.QueryTracer    = (*pgxTracer)(nil)
+	_ pgx.BatchTracer    = (*pgxTracer)(nil)
+	_ pgx.CopyFromTracer = (*pgxTracer)(nil)
+)
+
+// NewPgxPool creates a pgx pool from config for the paths that cannot afford
+// GORM. It returns nil when Config.Pgx is disabled. Connections are opened
+// lazily; HookPgxPool pings the pool on start.
+func NewPgxPool(
+	config Config,
+	logger *otelzap.Logger,
+	levels *applog.Levels,
+	redactor *redact.Policy,
+	metrics *QueryMetrics,
+) (*pgxpool.Pool, error) {
+	if !config.Pgx.Enable {
+		return nil, nil
+	}
+
+	poolConfig, err := pgxpool.ParseConfig(config.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("parse pgx pool config: %w", err)
+	}
+
+	poolConfig.MaxConns = int32(cmpOr(config.MaxOpenConns, defaultMaxOpenConns))
+	poolConfig.MinConns = config.Pgx.MinConns
+	poolConfig.MaxConnLifetime = time.Duration(config.ConnMaxLifetimeMillis) * time.Millisecond
+	poolConfig.MaxConnIdleTime = time.Duration(config.ConnMaxIdleTimeMillis) * time.Millisecond
+	if config.Pgx.HealthCheckPeriod > 0 {
+		poolConfig.HealthCheckPeriod = config.Pgx.HealthCheckPeriod
+	}
+	if config.PreferSimpleProtocol {
+		poolConfig.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+	}
+
+	poolConfig.ConnConfig.Tracer = &pgxTracer{
+		tracer: otel.Tracer("postgres"),
+		logger: NewZapLoggerAdapter(levels.Named(logger, "pgx"), queryLogConfig(config), redactor, metrics),
+	}
+
+	return pgxpool.NewWithConfig(context.Background(), poolConfig)
+}
+
+// HookPgxPool pings the pgx pool on start and closes it on stop.
+func HookPgxPool(lifecycle fx.Lifecycle, pool *pgxpool.Pool) {
+	if pool == nil {
+		return
+	}
+
+	lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error { return pool.Ping(ctx) },
+		OnStop: func(_ context.Context) error {
+			pool.Close()
+			return nil
+		},
+	})
+}
+
+// NewPgxChecks returns the health check of the pgx pool, if enabled.
+func NewPgxChecks(pool *pgxpool.Pool) []health.Check {
+	if pool == nil {
+		return nil
+	}
+
+	return []health.Check{{Name: "postgres_pgx", Checker: pgxChecker{pool: pool}}}
+}
+
+type pgxChecker struct {
+	pool *pgxpool.Pool
+}
+
+// CheckHealth implements health.Checker.
+func (checker pgxChecker) CheckHealth(ctx context.Context) error { return checker.pool.Ping(ctx) }
+
+// CopyFrom bulk loads items into table with COPY FROM, converting each item
+// to the values of columns. It returns the number of rows copied.
+func CopyFrom[T any](
+	ctx context.Context,
+	db Querier,
+	table string,
+	columns []string,
+	items []T,
+	values func(T) []any,
+) (int64, error) {
+	source := pgx.CopyFromSlice(len(items), func(i int) ([]any, error) {
+		return values(items[i]), nil
+	})
+
+	return db.CopyFrom(ctx, pgx.Identifier(strings.Split(table, ".")), columns, source)
+}
+
+// ExecBatch sends queries in a single round trip and returns their command
+// tags. It stops at the first failing query; run it in a transaction to make
+// the batch atomic.
+func ExecBatch(ctx context.Context, db Querier, queries []BatchQuery) ([]pgconn.CommandTag, error) {
+	batch := &pgx.Batch{}
+	for _, query := range queries {
+		batch.Queue(query.SQL, query.Args...)
+	}
+
+	results := db.SendBatch(ctx, batch)
+	tags := make([]pgconn.CommandTag, 0, len(queries))
+	for i := range queries {
+		tag, err := results.Exec()
+		if err != nil {
+			_ = results.Close()
+			return tags, fmt.Errorf("batch query %d: %w", i, err)
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, results.Close()
+}
+
+// TraceQueryStart implements pgx.QueryTracer.
+func (t *pgxTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return t.start(ctx, "pgx.query", data.SQL, semconv.DBQueryText(data.SQL))
+}
+
+// TraceQueryEnd implements pgx.QueryTracer.
+func (t *pgxTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	t.end(ctx, data.CommandTag.RowsAffected(), data.Err)
+}
+
+// TraceBatchStart implements pgx.BatchTracer.
+func (t *pgxTracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	return t.start(ctx, "pgx.batch", "", semconv.DBOperationBatchSize(data.Batch.Len()))
+}
+
+// TraceBatchQuery implements pgx.BatchTracer.
+func (t *pgxTracer) TraceBatchQuery(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchQueryData) {
+	state, ok := ctx.Value(pgxTraceKey{}).(*pgxTrace)
+	if !ok {
+		return
+	}
+
+	state.queries = append(state.queries, data.SQL)
+	state.rows += data.CommandTag.RowsAffected()
+	state.span.AddEvent("query", trace.WithAttributes(semconv.DBQueryText(data.SQL)))
+	if data.Err != nil {
+		state.span.RecordError(data.Err)
+	}
+}
+
+// TraceBatchEnd implements pgx.BatchTracer.
+func (t *pgxTracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	state, ok := ctx.Value(pgxTraceKey{}).(*pgxTrace)
+	if !ok {
+		return
+	}
+
+	state.sql = strings.Join(state.queries, "; ")
+	t.end(ctx, state.rows, data.Err)
+}
+
+// TraceCopyFromStart implements pgx.CopyFromTracer.
+func (t *pgxTracer) TraceCopyFromStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	sql := fmt.Sprintf("COPY %s (%s) FROM STDIN", data.TableName.Sanitize(), strings.Join(data.ColumnNames, ", "))
+	return t.start(ctx, "pgx.copy_from", sql, semconv.DBQueryText(sql))
+}
+
+// TraceCopyFromEnd implements pgx.CopyFromTracer.
+func (t *pgxTracer) TraceCopyFromEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	t.end(ctx, data.CommandTag.RowsAffected(), data.Err)
+}
+
+func (t *pgxTracer) start(ctx context.Context, name, sql string, attrs ...attribute.KeyValue) context.Context {
+	ctx, span := t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(append(attrs, semconv.DBSystemNamePostgreSQL)...),
+	)
+
+	return context.WithValue(ctx, pgxTraceKey{}, &pgxTrace{begin: time.Now(), span: span, sql: sql})
+}
+
+func (t *pgxTracer) end(ctx context.Context, rows int64, err error) {
+	state, ok := ctx.Value(pgxTraceKey{}).(*pgxTrace)
+	if !ok {
+		return
+	}
+
+	if err != nil {
+		state.span.RecordError(err)
+		state.span.SetStatus(codes.Error, err.Error())
+	}
+	state.span.End()
+
+	t.logger.Trace(ctx, state.begin, func() (string, int64) { return state.sql, rows }, err)
+}
@@ -0,0 +1,189 @@
+package pg
+
+import (
+	"database/sql"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/fx"
+)
+
+const (
+	lblDB = "db"
+
+	primaryName = "primary"
+)
+
+type (
+	// PoolCollector exports the connection pool statistics of every named
+	// database. Statistics are read on scrape, so the metrics never go stale.
+	PoolCollector struct {
+		mu   sync.RWMutex
+		sql  []namedSQLDB
+		pgx  []namedPgxPool
+		desc poolDescs
+	}
+
+	namedSQLDB struct {
+		name string
+		db   *sql.DB
+	}
+
+	namedPgxPool struct {
+		name string
+		stat func() *pgxpool.Stat
+	}
+
+	poolDescs struct {
+		maxOpen, open, inUse, idle                          *prometheus.Desc
+		waitCount, waitDuration                             *prometheus.Desc
+		maxIdleClosed, maxIdleTimeClosed, maxLifetimeClosed *prometheus.Desc
+
+		pgxMax, pgxTotal, pgxAcquired, pgxIdle, pgxConstructing *prometheus.Desc
+		pgxAcquireCount, pgxAcquireDuration                     *prometheus.Desc
+		pgxCanceledAcquire, pgxEmptyAcquire, pgxNew             *prometheus.Desc
+		pgxMaxLifetimeDestroy, pgxMaxIdleDestroy                *prometheus.Desc
+	}
+
+	// PoolMetricsParams holds the databases whose pools are exported.
+	PoolMetricsParams struct {
+		fx.In
+
+		Collector  *PoolCollector
+		SQLDB      *sql.DB
+		Replicas   *Replicas
+		Pool       *pgxpool.Pool
+		Registerer prometheus.Registerer
+	}
+)
+
+// NewPoolCollector creates an empty PoolCollector.
+func NewPoolCollector() *PoolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(name, help, []string{lblDB}, nil)
+	}
+
+	return &PoolCollector{desc: poolDescs{
+		maxOpen:           desc("db_pool_max_open_connections", "Maximum number of open connections to the database"),
+		open:              desc("db_pool_open_connections", "Number of established connections, both in use and idle"),
+		inUse:             desc("db_pool_in_use_connections", "Number of connections currently in use"),
+		idle:              desc("db_pool_idle_connections", "Number of idle connections"),
+		waitCount:         desc("db_pool_wait_count_total", "Total number of connections waited for"),
+		waitDuration:      desc("db_pool_wait_duration_seconds_total", "Total time blocked waiting for a new connection"),
+		maxIdleClosed:     desc("db_pool_max_idle_closed_total", "Total number of connections closed due to max_idle_conns"),
+		maxIdleTimeClosed: desc("db_pool_max_idle_time_closed_total", "Total number of connections closed due to conn_max_idle_time"),
+		maxLifetimeClosed: desc("db_pool_max_lifetime_closed_total", "Total number of connections closed due to conn_max_lifetime"),
+
+		pgxMax:                desc("db_pgx_pool_max_connections", "Maximum size of the pgx pool"),
+		pgxTotal:              desc("db_pgx_pool_total_connections", "Number of connections in the pgx pool, including constructing ones"),
+		pgxAcquired:           desc("db_pgx_pool_acquired_connections", "Number of connections currently acquired from the pgx pool"),
+		pgxIdle:               desc("db_pgx_pool_idle_connections", "Number of idle connections in the pgx pool"),
+		pgxConstructing:       desc("db_pgx_pool_constructing_connections", "Number of connections being established by the pgx pool"),
+		pgxAcquireCount:       desc("db_pgx_pool_acquire_total", "Total number of successful acquires from the pgx pool"),
+		pgxAcquireDuration:    desc("db_pgx_pool_acquire_duration_seconds_total", "Total time spent acquiring connections from the pgx pool"),
+		pgxCanceledAcquire:    desc("db_pgx_pool_canceled_acquire_total", "Total number of acquires canceled by their context"),
+		pgxEmptyAcquire:       desc("db_pgx_pool_empty_acquire_total", "Total number of acquires that waited for a connection"),
+		pgxNew:                desc("db_pgx_pool_new_connections_total", "Total number of connections opened by the pgx pool"),
+		pgxMaxLifetimeDestroy: desc("db_pgx_pool_max_lifetime_destroy_total", "Total number of connections closed due to max lifetime"),
+		pgxMaxIdleDestroy:     desc("db_pgx_pool_max_idle_destroy_total", "Total number of connections closed due to max idle time"),
+	}}
+}
+
+// AddSQL exports the pool statistics of db under name.
+func (c *PoolCollector) AddSQL(name string, db *sql.DB) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sql = append(c.sql, namedSQLDB{name: name, db: db})
+}
+
+// AddPgx exports the statistics returned by stat, usually pgxpool.Pool.Stat, under name.
+func (c *PoolCollector) AddPgx(name string, stat func() *pgxpool.Stat) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pgx = append(c.pgx, namedPgxPool{name: name, stat: stat})
+}
+
+// Describe implements prometheus.Collector.
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		c.desc.maxOpen, c.desc.open, c.desc.inUse, c.desc.idle,
+		c.desc.waitCount, c.desc.waitDuration,
+		c.desc.maxIdleClosed, c.desc.maxIdleTimeClosed, c.desc.maxLifetimeClosed,
+		c.desc.pgxMax, c.desc.pgxTotal, c.desc.pgxAcquired, c.desc.pgxIdle, c.desc.pgxConstructing,
+		c.desc.pgxAcquireCount, c.desc.pgxAcquireDuration,
+		c.desc.pgxCanceledAcquire, c.desc.pgxEmptyAcquire, c.desc.pgxNew,
+		c.desc.pgxMaxLifetimeDestroy, c.desc.pgxMaxIdleDestroy,
+	} {
+		ch <- desc
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, pool := range c.sql {
+		stats := pool.db.Stats()
+		gauge := func(desc *prometheus.Desc, value float64) {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, pool.name)
+		}
+		counter := func(desc *prometheus.Desc, value float64) {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value, pool.name)
+		}
+
+		gauge(c.desc.maxOpen, float64(stats.MaxOpenConnections))
+		gauge(c.desc.open, float64(stats.OpenConnections))
+		gauge(c.desc.inUse, float64(stats.InUse))
+		gauge(c.desc.idle, float64(stats.Idle))
+		counter(c.desc.waitCount, float64(stats.WaitCount))
+		counter(c.desc.waitDuration, stats.WaitDuration.Seconds())
+		counter(c.desc.maxIdleClosed, float64(stats.MaxIdleClosed))
+		counter(c.desc.maxIdleTimeClosed, float64(stats.MaxIdleTimeClosed))
+		counter(c.desc.maxLifetimeClosed, float64(stats.MaxLifetimeClosed))
+	}
+
+	for _, pool := range c.pgx {
+		stat := pool.stat()
+		if stat == nil {
+			continue
+		}
+
+		gauge := func(desc *prometheus.Desc, value float64) {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, pool.name)
+		}
+		counter := func(desc *prometheus.Desc, value float64) {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value, pool.name)
+		}
+
+		gauge(c.desc.pgxMax, float64(stat.MaxConns()))
+		gauge(c.desc.pgxTotal, float64(stat.TotalConns()))
+		gauge(c.desc.pgxAcquired, float64(stat.AcquiredConns()))
+		gauge(c.desc.pgxIdle, float64(stat.IdleConns()))
+		gauge(c.desc.pgxConstructing, float64(stat.ConstructingConns()))
+		counter(c.desc.pgxAcquireCount, float64(stat.AcquireCount()))
+		counter(c.desc.pgxAcquireDuration, stat.AcquireDuration().Seconds())
+		counter(c.desc.pgxCanceledAcquire, float64(stat.CanceledAcquireCount()))
+		counter(c.desc.pgxEmptyAcquire, float64(stat.EmptyAcquireCount()))
+		counter(c.desc.pgxNew, float64(stat.NewConnsCount()))
+		counter(c.desc.pgxMaxLifetimeDestroy, float64(stat.MaxLifetimeDestroyCount()))
+		counter(c.desc.pgxMaxIdleDestroy, float64(stat.MaxIdleDestroyCount()))
+	}
+}
+
+// RegisterPoolMetrics exports the pools of the primary, of every replica and
+// of the pgx pool when enabled, and registers the collector.
+func RegisterPoolMetrics(params PoolMetricsParams) error {
+	params.Collector.AddSQL(primaryName, params.SQLDB)
+	for _, replica := range params.Replicas.List() {
+		params.Collector.AddSQL(replica.Name, replica.DB)
+	}
+	if params.Pool != nil {
+		params.Collector.AddPgx(primaryName, params.Pool.Stat)
+	}
+
+	return params.Registerer.Register(params.Collector)
+}
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	applog "github.com/widnyana/wasabi/internal/adapter/logger"
+	"github.com/widnyana/wasabi/internal/adapter/redact"
 	"go.opentelemetry.io/otel"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -30,6 +32,10 @@ type Config struct {
 	ConnMaxLifetimeMillis int `envconfig:"conn_max_lifetime_millis" default:"7200000"` // Default to 2 hours in milliseconds
 	MaxIdleConns          int `envconfig:"max_idle_conns" default:"10"`
 	MaxOpenConns          int `envconfig:"max_open_conns" default:"50"`
+
+	Replicas ReplicaConfig `envconfig:"replicas"`
+	Tx       TxConfig      `envconfig:"tx"`
+	Pgx      PgxConfig     `envconfig:"pgx"`
 }
 
 // NewGorm initializes a new GORM database connection for PostgreSQL.
@@ -39,18 +45,21 @@ type Config struct {
 // and a custom logger adapter that integrates with Zap and OpenTelemetry.
 // It also retrieves the underlying `sql.DB` instance to configure the connection pool
 // using the provided configuration and performs an initial connection check.
+// SQL statements and their parameters are logged through the redaction policy,
+// and every query is recorded by the query metrics.
 // Returns a GORM database instance or an error if the connection fails.
-func NewGorm(config Config, logger *otelzap.Logger) (*gorm.DB, error) {
+func NewGorm(
+	config Config,
+	logger *otelzap.Logger,
+	levels *applog.Levels,
+	redactor *redact.Policy,
+	metrics *QueryMetrics,
+) (*gorm.DB, error) {
 	ctx, span := otel.Tracer("postgres").Start(context.TODO(), "new-gorm")
 	defer span.End()
 
 	logger.Ctx(ctx).Info("initializing gorm postgresql connection")
 
-	level := gormlogger.Error
-	if config.Debug {
-		level = gormlogger.Info
-	}
-
 	db, err := gorm.Open(
 		postgres.New(postgres.Config{
 			DSN:                  config.DSN,
@@ -61,12 +70,7 @@ func NewGorm(config Config, logger *otelzap.Logger) (*gorm.DB, error) {
 			PrepareStmt:            true, // https://gorm.io/docs/performance.html#SQL-Builder-with-PreparedStmt
 			SkipDefaultTransaction: true, // https://gorm.io/docs/performance.html#Disable-Default-Transaction
 			FullSaveAssociations:   false,
-			Logger: NewZapLoggerAdapter(logger, gormlogger.Config{
-				LogLevel:                  level,
-				SlowThreshold:             time.Duration(config.SlowThresholdMS) * time.Millisecond,
-				ParameterizedQueries:      true, // Don't include params in the SQL log,
-				IgnoreRecordNotFoundError: true, // Ignore ErrRecordNotFound error for logger
-			}),
+			Logger:                 NewZapLoggerAdapter(levels.Named(logger, "gorm"), queryLogConfig(config), redactor, metrics),
 		},
 	)
 	if err != nil {
@@ -76,6 +80,22 @@ func NewGorm(config Config, logger *otelzap.Logger) (*gorm.DB, error) {
 	return db, nil
 }
 
+// queryLogConfig returns the configuration of the query logger shared by
+// GORM and the pgx pool.
+func queryLogConfig(config Config) gormlogger.Config {
+	level := gormlogger.Error
+	if config.Debug {
+		level = gormlogger.Info
+	}
+
+	return gormlogger.Config{
+		LogLevel:                  level,
+		SlowThreshold:             time.Duration(config.SlowThresholdMS) * time.Millisecond,
+		ParameterizedQueries:      true, // Don't include params in the SQL log,
+		IgnoreRecordNotFoundError: true, // Ignore ErrRecordNotFound error for logger
+	}
+}
+
 // NewSQLDB extracts the underlying *sql.DB instance from the provided GORM database connection.
 // This allows direct access to the standard Go SQL driver's functionality, such as
 // connection pool management and lower-level database operations if needed.
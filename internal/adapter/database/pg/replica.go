@@ -0,0 +1,320 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"github.com/widnyana/wasabi/internal/adapter/health"
+	applog "github.com/widnyana/wasabi/internal/adapter/logger"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+const (
+	PolicyRoundRobin = "round_robin"
+	PolicyRandom     = "random"
+
+	// replicationLagQuery returns 0 when the replica replayed everything it
+	// received, so an idle primary is not mistaken for lag.
+	replicationLagQuery = `SELECT CASE
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+	END`
+)
+
+var (
+	ErrUnknownPolicy   = errors.New("unknown replica routing policy")
+	ErrReplicaLagging  = errors.New("replica replication lag exceeds the maximum")
+	ErrReplicaExcluded = errors.New("replica excluded from routing")
+	ErrCheckInterval   = errors.New("replica check interval must be positive")
+)
+
+type (
+	// ReplicaConfig configures the read replicas. Pool settings left to zero
+	// are inherited from the primary.
+	ReplicaConfig struct {
+		DSNs   []string `envconfig:"dsns"`
+		Policy string   `envconfig:"policy" default:"round_robin"`
+		// MaxLag excludes replicas lagging further behind the primary.
+		// Zero disables the lag checks.
+		MaxLag        time.Duration `envconfig:"max_lag"`
+		CheckInterval time.Duration `envconfig:"check_interval" default:"5s"`
+
+		ConnMaxIdleTimeMillis int `envconfig:"conn_max_idle_time_millis"`
+		ConnMaxLifetimeMillis int `envconfig:"conn_max_lifetime_millis"`
+		MaxIdleConns          int `envconfig:"max_idle_conns"`
+		MaxOpenConns          int `envconfig:"max_open_conns"`
+	}
+
+	// Replica is a read replica with its own connection pool.
+	Replica struct {
+		Name string
+		DB   *sql.DB
+
+		lag      atomic.Int64
+		excluded atomic.Bool
+	}
+
+	// Replicas routes reads to the replicas that are reachable and within
+	// the allowed replication lag. Writes, transactions, locking reads and
+	// contexts marked with WithPrimary go to the primary.
+	Replicas struct {
+		replicas []*Replica
+		byPool   map[gorm.ConnPool]*Replica
+		policy   dbresolver.Policy
+		cfg      ReplicaConfig
+		logger   *otelzap.Logger
+		healthy  atomic.Int32
+	}
+
+	primaryCtxKey struct{}
+)
+
+// WithPrimary returns a copy of ctx whose queries all go to the primary,
+// for reads that must see the writes made just before.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryCtxKey{}, true)
+}
+
+// UsesPrimary reports whether ctx was marked with WithPrimary.
+func UsesPrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryCtxKey{}).(bool)
+	return primary
+}
+
+// NewReplicas opens a pool per replica DSN and applies its pool settings.
+// It fails on a non-positive CheckInterval when replicas are configured.
+func NewReplicas(config Config, logger *otelzap.Logger) (*Replicas, error) {
+	cfg := config.Replicas
+
+	var base dbresolver.Policy
+	switch cfg.Policy {
+	case PolicyRoundRobin, "":
+		base = dbresolver.StrictRoundRobinPolicy()
+	case PolicyRandom:
+		base = dbresolver.RandomPolicy{}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPolicy, cfg.Policy)
+	}
+
+	if len(cfg.DSNs) > 0 && cfg.CheckInterval <= 0 {
+		return nil, fmt.Errorf("%w: %s", ErrCheckInterval, cfg.CheckInterval)
+	}
+
+	replicas := &Replicas{
+		byPool: make(map[gorm.ConnPool]*Replica, len(cfg.DSNs)),
+		policy: base,
+		cfg:    cfg,
+		logger: logger,
+	}
+
+	pool := config
+	pool.ConnMaxIdleTimeMillis = cmpOr(cfg.ConnMaxIdleTimeMillis, config.ConnMaxIdleTimeMillis)
+	pool.ConnMaxLifetimeMillis = cmpOr(cfg.ConnMaxLifetimeMillis, config.ConnMaxLifetimeMillis)
+	pool.MaxIdleConns = cmpOr(cfg.MaxIdleConns, config.MaxIdleConns)
+	pool.MaxOpenConns = cmpOr(cfg.MaxOpenConns, config.MaxOpenConns)
+
+	for i, dsn := range cfg.DSNs {
+		connConfig, err := pgx.ParseConfig(dsn)
+		if err != nil {
+			return nil, errors.Join(err, replicas.Close())
+		}
+		if config.PreferSimpleProtocol {
+			connConfig.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+		}
+
+		replica := &Replica{Name: fmt.Sprintf("replica_%d", i), DB: stdlib.OpenDB(*connConfig)}
+		applyPoolSetting(replica.DB, pool, logger.WithOptions(zap.Fields(zap.String("replica", replica.Name))))
+
+		replicas.replicas = append(replicas.replicas, replica)
+		replicas.byPool[replica.DB] = replica
+	}
+	replicas.healthy.Store(int32(len(replicas.replicas)))
+
+	return replicas, nil
+}
+
+func cmpOr(value, fallback int) int {
+	if value != 0 {
+		return value
+	}
+	return fallback
+}
+
+// List returns the replicas.
+func (r *Replicas) List() []*Replica { return r.replicas }
+
+// Resolve implements dbresolver.Policy, skipping the excluded replicas.
+func (r *Replicas) Resolve(pools []gorm.ConnPool) gorm.ConnPool {
+	candidates := make([]gorm.ConnPool, 0, len(pools))
+	for _, pool := range pools {
+		if replica, ok := r.byPool[pool]; !ok || !replica.excluded.Load() {
+			candidates = append(candidates, pool)
+		}
+	}
+
+	// EnableReplicas routes to the primary when every replica is excluded,
+	// this only happens when one got excluded concurrently.
+	if len(candidates) == 0 {
+		candidates = pools
+	}
+
+	return r.policy.Resolve(candidates)
+}
+
+// routePrimary runs before dbresolver and marks the statement as a write
+// when the context asks for the primary or no replica can serve it.
+func (r *Replicas) routePrimary(db *gorm.DB) {
+	if UsesPrimary(db.Statement.Context) || r.healthy.Load() == 0 {
+		dbresolver.Write.ModifyStatement(db.Statement)
+	}
+}
+
+// Check checks a replica and excludes it from routing when it is
+// unreachable or lagging behind.
+func (r *Replicas) Check(ctx context.Context, replica *Replica) error {
+	err := replica.DB.PingContext(ctx)
+	if err == nil && r.cfg.MaxLag > 0 {
+		var seconds float64
+		if err = replica.DB.QueryRowContext(ctx, replicationLagQuery).Scan(&seconds); err == nil {
+			lag := time.Duration(seconds * float64(time.Second))
+			replica.lag.Store(int64(lag))
+			if lag > r.cfg.MaxLag {
+				err = fmt.Errorf("%w: %s > %s", ErrReplicaLagging, lag, r.cfg.MaxLag)
+			}
+		}
+	}
+
+	excluded := err != nil
+	if replica.excluded.Swap(excluded) != excluded {
+		if excluded {
+			r.healthy.Add(-1)
+			applog.Ctx(r.logger, ctx).Warn("replica excluded from routing", zap.String("replica", replica.Name), zap.Error(err))
+		} else {
+			r.healthy.Add(1)
+			applog.Ctx(r.logger, ctx).Info("replica back in routing", zap.String("replica", replica.Name))
+		}
+	}
+
+	return err
+}
+
+// Lag returns the last replication lag measured on the replica.
+func (replica *Replica) Lag() time.Duration { return time.Duration(replica.lag.Load()) }
+
+// monitor checks every replica each CheckInterval until ctx is done.
+func (r *Replicas) monitor(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		for _, replica := range r.replicas {
+			checkCtx, cancel := context.WithTimeout(ctx, r.cfg.CheckInterval)
+			_ = r.Check(checkCtx, replica)
+			cancel()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close closes the pools of the replicas.
+func (r *Replicas) Close() error {
+	var errs []error
+	for _, replica := range r.replicas {
+		errs = append(errs, replica.DB.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// EnableReplicas registers the replicas on the GORM connection. Reads go to
+// the replicas, everything else stays on the primary. Replicas lag behind,
+// so callers that read their own writes, or whose reads decide what gets
+// cached, must run them with WithPrimary(ctx) or Clauses(dbresolver.Write).
+func EnableReplicas(db *gorm.DB, replicas *Replicas) error {
+	if len(replicas.replicas) == 0 {
+		return nil
+	}
+
+	dialectors := make([]gorm.Dialector, 0, len(replicas.replicas))
+	for _, replica := range replicas.replicas {
+		dialectors = append(dialectors, postgres.New(postgres.Config{Conn: replica.DB}))
+	}
+
+	err := db.Use(dbresolver.Register(dbresolver.Config{Replicas: dialectors, Policy: replicas}))
+	if err != nil {
+		return err
+	}
+
+	// dbresolver registers before "*" too; GORM runs the callbacks registered
+	// before "*" last-registered first, so routePrimary runs ahead of it.
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Query().Before("*").Register("wasabi:route_primary", replicas.routePrimary),
+		callbacks.Row().Before("*").Register("wasabi:route_primary", replicas.routePrimary),
+		callbacks.Raw().Before("*").Register("wasabi:route_primary", replicas.routePrimary),
+	)
+}
+
+// HookReplicas checks the replicas in the background while the application
+// runs, and closes their pools on stop.
+func HookReplicas(lifecycle fx.Lifecycle, replicas *Replicas) {
+	if len(replicas.replicas) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				replicas.monitor(ctx)
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			<-done
+			return replicas.Close()
+		},
+	})
+}
+
+// NewReplicaChecks contributes a health check per replica.
+func NewReplicaChecks(replicas *Replicas) []health.Check {
+	checks := make([]health.Check, 0, len(replicas.replicas))
+	for _, replica := range replicas.replicas {
+		checks = append(checks, health.Check{
+			Name:    "postgres_" + replica.Name,
+			Checker: replicaChecker{replicas: replicas, replica: replica},
+		})
+	}
+
+	return checks
+}
+
+type replicaChecker struct {
+	replicas *Replicas
+	replica  *Replica
+}
+
+// CheckHealth implements health.Checker.
+func (checker replicaChecker) CheckHealth(ctx context.Context) error {
+	return checker.replicas.Check(ctx, checker.replica)
+}
@@ -0,0 +1,260 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	applog "github.com/widnyana/wasabi/internal/adapter/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+	sqlStateUniqueViolation      = "23505"
+
+	maxRetryBackoff = time.Second
+)
+
+var ErrUnknownIsolation = errors.New("unknown transaction isolation level")
+
+type (
+	// TxConfig configures the transactions run by TxManager.
+	TxConfig struct {
+		// Isolation is the default isolation level: read_committed,
+		// repeatable_read or serializable. Empty uses the server default.
+		Isolation string `envconfig:"isolation"`
+		// MaxRetries is how many times a transaction is retried after a
+		// serialization failure or a deadlock.
+		MaxRetries   int           `envconfig:"max_retries" default:"3"`
+		RetryBackoff time.Duration `envconfig:"retry_backoff" default:"20ms"`
+	}
+
+	// TxOption customizes a single transaction.
+	TxOption func(*txOptions)
+
+	txOptions struct {
+		isolation  sql.IsolationLevel
+		readOnly   bool
+		maxRetries int
+	}
+
+	// TxManager runs functions inside a transaction carried by the context,
+	// so repositories join it through DB without passing *gorm.DB around.
+	TxManager struct {
+		db      *gorm.DB
+		options txOptions
+		backoff time.Duration
+		tracer  trace.Tracer
+		logger  *otelzap.Logger
+	}
+
+	txCtxKey struct{}
+
+	txState struct {
+		tx          *gorm.DB
+		depth       int
+		afterCommit []func(ctx context.Context)
+	}
+)
+
+// WithIsolation sets the isolation level of the transaction. It is ignored
+// by nested transactions, which run in a savepoint of the outer one.
+func WithIsolation(level sql.IsolationLevel) TxOption {
+	return func(options *txOptions) { options.isolation = level }
+}
+
+// ReadOnly starts a read only transaction.
+func ReadOnly() TxOption {
+	return func(options *txOptions) { options.readOnly = true }
+}
+
+// WithRetries overrides TxConfig.MaxRetries for the transaction.
+func WithRetries(n int) TxOption {
+	return func(options *txOptions) { options.maxRetries = n }
+}
+
+// NewTxManager creates a new TxManager on the primary connection.
+func NewTxManager(db *gorm.DB, config Config, logger *otelzap.Logger) (*TxManager, error) {
+	isolation, err := parseIsolation(config.Tx.Isolation)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TxManager{
+		db:      db,
+		options: txOptions{isolation: isolation, maxRetries: config.Tx.MaxRetries},
+		backoff: config.Tx.RetryBackoff,
+		tracer:  otel.Tracer("postgres"),
+		logger:  logger,
+	}, nil
+}
+
+// DB returns the transaction carried by ctx, or the connection bound to ctx
+// outside of a transaction.
+func (m *TxManager) DB(ctx context.Context) *gorm.DB {
+	if state, ok := ctx.Value(txCtxKey{}).(*txState); ok {
+		return state.tx.WithContext(ctx)
+	}
+
+	return m.db.WithContext(ctx)
+}
+
+// InTx reports whether ctx carries a transaction.
+func InTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txCtxKey{}).(*txState)
+	return ok
+}
+
+// AfterCommit schedules fn to run once the transaction carried by ctx has
+// committed, and reports whether ctx carries one. Functions scheduled in a
+// savepoint that is rolled back, or in an attempt that is retried, never run.
+// Callers run fn themselves when it returns false.
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) bool {
+	state, ok := ctx.Value(txCtxKey{}).(*txState)
+	if ok {
+		state.afterCommit = append(state.afterCommit, fn)
+	}
+
+	return ok
+}
+
+// Do runs fn in a transaction, committed when fn returns nil and rolled back
+// otherwise. When ctx already carries a transaction, fn runs in a savepoint
+// of it. Outer transactions are retried on serialization failures and
+// deadlocks; fn must therefore be safe to run again.
+func (m *TxManager) Do(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error {
+	if state, ok := ctx.Value(txCtxKey{}).(*txState); ok {
+		return m.savepoint(ctx, state, fn)
+	}
+
+	options := m.options
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := m.run(ctx, options, attempt, fn)
+		if err == nil || !IsRetryable(err) || attempt >= options.maxRetries {
+			return err
+		}
+
+		backoff := m.retryBackoff(attempt)
+		applog.Ctx(m.logger, ctx).Warn("retrying transaction",
+			zap.Int("attempt", attempt+1), zap.Duration("backoff", backoff), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(backoff):
+		}
+	}
+}
+
+func (m *TxManager) run(ctx context.Context, options txOptions, attempt int, fn func(ctx context.Context) error) error {
+	ctx, span := m.tracer.Start(ctx, "db.transaction", trace.WithAttributes(
+		attribute.String("db.transaction.isolation", options.isolation.String()),
+		attribute.Bool("db.transaction.read_only", options.readOnly),
+		attribute.Int("db.transaction.attempt", attempt),
+	))
+	defer span.End()
+
+	var state *txState
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		state = &txState{tx: tx}
+		return fn(context.WithValue(ctx, txCtxKey{}, state))
+	}, &sql.TxOptions{Isolation: options.isolation, ReadOnly: options.readOnly})
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	for _, callback := range state.afterCommit {
+		callback(ctx)
+	}
+
+	return nil
+}
+
+// savepoint runs fn in a savepoint of the outer transaction, rolled back to
+// when fn fails without aborting the outer transaction.
+func (m *TxManager) savepoint(ctx context.Context, state *txState, fn func(ctx context.Context) error) error {
+	depth := state.depth + 1
+	ctx, span := m.tracer.Start(ctx, "db.savepoint", trace.WithAttributes(
+		attribute.Int("db.transaction.depth", depth),
+	))
+	defer span.End()
+
+	// GORM turns a transaction started on a transaction into a savepoint.
+	nested := &txState{depth: depth}
+	err := state.tx.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		nested.tx = tx
+		return fn(context.WithValue(ctx, txCtxKey{}, nested))
+	})
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	// A released savepoint commits with the outer transaction.
+	state.afterCommit = append(state.afterCommit, nested.afterCommit...)
+
+	return nil
+}
+
+func (m *TxManager) retryBackoff(attempt int) time.Duration {
+	backoff := min(m.backoff<<attempt, maxRetryBackoff)
+	if backoff <= 0 {
+		return 0
+	}
+
+	// Full jitter keeps concurrent retries of conflicting transactions apart.
+	return time.Duration(rand.Int64N(int64(backoff)))
+}
+
+// IsRetryable reports whether err is a serialization failure or a deadlock,
+// after which the whole transaction can be retried.
+func IsRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	return pgErr.Code == sqlStateSerializationFailure || pgErr.Code == sqlStateDeadlockDetected
+}
+
+// IsUniqueViolation reports whether err is a unique constraint violation.
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == sqlStateUniqueViolation
+}
+
+func parseIsolation(level string) (sql.IsolationLevel, error) {
+	switch level {
+	case "":
+		return sql.LevelDefault, nil
+	case "read_committed":
+		return sql.LevelReadCommitted, nil
+	case "repeatable_read":
+		return sql.LevelRepeatableRead, nil
+	case "serializable":
+		return sql.LevelSerializable, nil
+	default:
+		return sql.LevelDefault, fmt.Errorf("%w: %s", ErrUnknownIsolation, level)
+	}
+}
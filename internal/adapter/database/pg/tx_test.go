@@ -0,0 +1,19 @@
+package pg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryBackoffStaysWithinCap(t *testing.T) {
+	m := &TxManager{backoff: 20 * time.Millisecond}
+
+	for attempt := range 10 {
+		ceiling := min(m.backoff<<attempt, maxRetryBackoff)
+		for range 100 {
+			if got := m.retryBackoff(attempt); got < 0 || got >= ceiling {
+				t.Fatalf("retryBackoff(%d) = %v, want [0, %v)", attempt, got, ceiling)
+			}
+		}
+	}
+}
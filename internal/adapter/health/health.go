@@ -0,0 +1,104 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/fx"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+
+	defaultCheckTimeout = 5 * time.Second
+)
+
+type (
+	// Checker checks the health of a dependency.
+	Checker interface {
+		CheckHealth(ctx context.Context) error
+	}
+
+	// Check is a named Checker contributed to the "health_checks" value group.
+	Check struct {
+		Name    string
+		Checker Checker
+	}
+
+	// Result is the outcome of a single check.
+	Result struct {
+		Status   string `json:"status"`
+		Error    string `json:"error,omitempty"`
+		Duration string `json:"duration"`
+	}
+
+	// Report aggregates the results of all checks.
+	Report struct {
+		Status string            `json:"status"`
+		Checks map[string]Result `json:"checks"`
+	}
+
+	// Registry runs the registered checks.
+	Registry struct {
+		checks []Check
+	}
+
+	// Params holds the checks collected from the "health_checks" value group.
+	Params struct {
+		fx.In
+
+		Checks []Check `group:"health_checks"`
+	}
+)
+
+// Module provides the health Registry.
+var Module = fx.Module("health", fx.Provide(NewRegistry))
+
+// AsCheck annotates a constructor returning a Check so it joins the "health_checks" value group.
+func AsCheck(constructor any) any {
+	return fx.Annotate(constructor, fx.ResultTags(`group:"health_checks"`))
+}
+
+// NewRegistry creates a new Registry.
+func NewRegistry(params Params) *Registry {
+	return &Registry{checks: params.Checks}
+}
+
+// Report runs every check concurrently. The report is up only when all checks pass.
+func (registry *Registry) Report(ctx context.Context) Report {
+	ctx, cancel := context.WithTimeout(ctx, defaultCheckTimeout)
+	defer cancel()
+
+	report := Report{Status: StatusUp, Checks: make(map[string]Result, len(registry.checks))}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, check := range registry.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			start := time.Now()
+			err := check.Checker.CheckHealth(ctx)
+			result := Result{Status: StatusUp, Duration: time.Since(start).String()}
+			if err != nil {
+				result.Status = StatusDown
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[check.Name] = result
+			if err != nil {
+				report.Status = StatusDown
+			}
+		}()
+	}
+	wg.Wait()
+
+	return report
+}
@@ -3,7 +3,25 @@ package http
 // Config represents the configuration for the HTTP server.
 type (
 	Config struct {
-		Port int    `mapstructure:"PORT"`
-		Host string `mapstructure:"HOST" default:"127.0.0.1"`
+		Port  int         `mapstructure:"PORT"`
+		Host  string      `mapstructure:"HOST" default:"127.0.0.1"`
+		Probe ProbeConfig `envconfig:"probe"`
+		// ReadinessPath serves the health status on the public port, 503 when
+		// any check is down. Empty disables it.
+		ReadinessPath string `envconfig:"readiness_path" default:"/readyz"`
+	}
+
+	// ProbeConfig represents the configuration for the HTTP request probes.
+	ProbeConfig struct {
+		// Disabled lists the names of probes that are not mounted.
+		Disabled []string `envconfig:"disabled"`
+		// Order lists probe names in the order they run; unlisted probes run after them.
+		Order []string `envconfig:"order" default:"prometheus"`
+
+		// DurationBuckets are the upper bounds, in seconds, of the latency histogram.
+		DurationBuckets []float64 `envconfig:"duration_buckets" default:"0.005,0.01,0.025,0.05,0.1,0.25,0.5,1,2.5,5,10"`
+		// ConstLabels are extra labels with fixed values added to every series,
+		// e.g. region:eu-west-1,tier:public.
+		ConstLabels map[string]string `envconfig:"const_labels"`
 	}
 )
@@ -11,7 +11,9 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/compress"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	applog "github.com/widnyana/wasabi/internal/adapter/logger"
 	"go.opentelemetry.io/otel"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
@@ -24,14 +26,17 @@ const (
 )
 
 // Probe is an interface for HTTP request probes.
+// Probes are collected from the "probes" value group, see AsProbe.
 type (
 	Probe interface {
+		// Name identifies the probe in ProbeConfig.
+		Name() string
 		Middleware(*fiber.Ctx) error
 	}
 )
 
 // NewFiber creates a new Fiber app.
-func NewFiber(logger *otelzap.Logger, probe Probe) *fiber.App {
+func NewFiber(logger *otelzap.Logger, probes ProbeChain) *fiber.App {
 	app := fiber.New(fiber.Config{
 		AppName:               "wasabi",
 		Concurrency:           fiberConcurrency,
@@ -47,6 +52,8 @@ func NewFiber(logger *otelzap.Logger, probe Probe) *fiber.App {
 		otelfiber.WithServerName("wasabi"),
 		otelfiber.WithTracerProvider(otel.GetTracerProvider()),
 	))
+	app.Use(requestid.New())
+	app.Use(ContextFields)
 	app.Use(recover.New())
 	app.Use(compress.New(compress.Config{
 		Level: compress.LevelBestSpeed,
@@ -54,17 +61,30 @@ func NewFiber(logger *otelzap.Logger, probe Probe) *fiber.App {
 
 	app.Use(fiberzap.New(fiberzap.Config{
 		Logger: logger.Logger,
+		FieldsFunc: func(ctx *fiber.Ctx) []zap.Field {
+			return applog.FieldsFromContext(ctx.UserContext())
+		},
 	}))
 
-	app.Use(probe.Middleware)
+	for _, probe := range probes {
+		app.Use(probe.Middleware)
+	}
 
 	return app
 }
 
 // HookFiber hooks the Fiber app to the lifecycle.
-func HookFiber(lifecycle fx.Lifecycle, app *fiber.App, config Config, logger *otelzap.Logger) {
+// The start gates run first; the server only listens once they all passed.
+func HookFiber(lifecycle fx.Lifecycle, app *fiber.App, config Config, logger *otelzap.Logger, gates StartGates) {
 	lifecycle.Append(fx.Hook{
-		OnStart: func(context.Context) error {
+		OnStart: func(ctx context.Context) error {
+			for _, gate := range gates {
+				applog.Ctx(logger, ctx).Info("running start gate", zap.String("gate", gate.Name))
+				if err := gate.Run(ctx); err != nil {
+					return fmt.Errorf("start gate %s: %w", gate.Name, err)
+				}
+			}
+
 			go func() {
 				port := config.Port
 				if port == 0 {
@@ -0,0 +1,46 @@
+package http
+
+import (
+	"sync/atomic"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	applog "github.com/widnyana/wasabi/internal/adapter/logger"
+	"go.uber.org/zap"
+)
+
+// routeField resolves the matched route when an entry is encoded, because
+// middlewares mounted with Use run before routing. It is frozen once the
+// request ends, so entries logged afterwards never read the recycled fiber.Ctx.
+type routeField struct {
+	ctx    *fiber.Ctx
+	frozen atomic.Pointer[string]
+}
+
+// String implements fmt.Stringer.
+func (field *routeField) String() string {
+	if route := field.frozen.Load(); route != nil {
+		return *route
+	}
+
+	return field.ctx.Route().Path
+}
+
+// ContextFields attaches the request ID, method and route to the user context
+// so that every entry logged through logger.Ctx carries them.
+func ContextFields(ctx *fiber.Ctx) error {
+	route := &routeField{ctx: ctx}
+
+	fields := []zap.Field{zap.String("method", ctx.Method()), zap.Stringer("route", route)}
+	if id, ok := ctx.Locals(requestid.ConfigDefault.ContextKey).(string); ok && id != "" {
+		fields = append(fields, zap.String("request_id", id))
+	}
+	ctx.SetUserContext(applog.WithFields(ctx.UserContext(), fields...))
+
+	err := ctx.Next()
+
+	path := ctx.Route().Path
+	route.frozen.Store(&path)
+
+	return err
+}
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"context"
+	"sort"
+
+	"go.uber.org/fx"
+)
+
+type (
+	// StartGate runs before the HTTP server starts serving, for work such as
+	// schema migrations. A failing gate aborts the application start.
+	StartGate struct {
+		Name string
+		Run  func(ctx context.Context) error
+	}
+
+	// StartGates are the gates run by HookFiber, ordered by name.
+	StartGates []StartGate
+
+	// StartGateParams holds the gates collected from the "start_gates" value group.
+	StartGateParams struct {
+		fx.In
+
+		Gates []StartGate `group:"start_gates"`
+	}
+)
+
+// AsStartGate annotates a constructor so its StartGate result joins the
+// "start_gates" value group.
+//
+//	fx.Provide(http.AsStartGate(NewMigrationGate))
+func AsStartGate(constructor any) any {
+	return fx.Annotate(constructor, fx.ResultTags(`group:"start_gates"`))
+}
+
+// NewStartGates drops the gates without Run and orders the rest by name,
+// as fx does not guarantee group order.
+func NewStartGates(params StartGateParams) StartGates {
+	gates := make(StartGates, 0, len(params.Gates))
+	for _, gate := range params.Gates {
+		if gate.Run != nil {
+			gates = append(gates, gate)
+		}
+	}
+
+	sort.SliceStable(gates, func(i, j int) bool { return gates[i].Name < gates[j].Name })
+
+	return gates
+}
@@ -7,11 +7,14 @@ var (
 
 	FiberProviders = fx.Options(
 		fx.Provide(NewFiber),
+		fx.Provide(NewProbeChain),
+		fx.Provide(NewStartGates),
 		fx.Provide(NewPromProbe),
-		fx.Provide(func(probe *PromProbe) Probe { return probe }),
+		fx.Provide(fx.Annotate(func(probe *PromProbe) Probe { return probe }, fx.ResultTags(`group:"probes"`))),
 	)
 
 	FiberInvokes = fx.Options(
 		fx.Invoke(HookFiber),
+		fx.Invoke(ServeReadiness),
 	)
 )
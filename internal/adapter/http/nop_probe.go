@@ -5,12 +5,16 @@ import (
 	"go.uber.org/fx"
 )
 
-// NopProbeProvider provides a NopProbe.
-var NopProbeProvider = fx.Decorate(func() Probe { return NopProbe{} })
+// NopProbeProvider replaces the probe chain with a single NopProbe,
+// disabling every registered probe.
+var NopProbeProvider = fx.Decorate(func() ProbeChain { return ProbeChain{NopProbe{}} })
 
 // NopProbe is a probe that does nothing.
 type NopProbe struct{}
 
+// Name implements Probe.
+func (probe NopProbe) Name() string { return "nop" }
+
 // Middleware is a middleware that does nothing.
 func (probe NopProbe) Middleware(ctx *fiber.Ctx) error {
 	return ctx.Next()
@@ -0,0 +1,42 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+
+	"github.com/widnyana/wasabi/internal/adapter/cli"
+)
+
+const specFileMode = 0o644
+
+// NewCommand creates the `openapi` command that writes the document to disk,
+// so CI can diff it against the committed spec.
+func NewCommand(registry *Registry) *cli.Command {
+	return &cli.Command{
+		Name:  "openapi",
+		Usage: "[-o openapi.json]",
+		Short: "write the OpenAPI document of the registered routes",
+		Run: func(_ context.Context, args []string) error {
+			flags := flag.NewFlagSet("openapi", flag.ContinueOnError)
+			output := flags.String("o", "openapi.json", "output file, - for stdout")
+			if err := flags.Parse(args); err != nil {
+				return err
+			}
+
+			spec, err := json.MarshalIndent(registry.Document(), "", "  ")
+			if err != nil {
+				return err
+			}
+			spec = append(spec, '\n')
+
+			if *output == "-" {
+				_, err = os.Stdout.Write(spec)
+				return err
+			}
+
+			return os.WriteFile(*output, spec, specFileMode)
+		},
+	}
+}
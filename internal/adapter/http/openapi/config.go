@@ -0,0 +1,12 @@
+package openapi
+
+// Config represents the configuration for the OpenAPI document and UI.
+type Config struct {
+	Title       string `envconfig:"title" default:"wasabi"`
+	Description string `envconfig:"description"`
+	// Path serves the generated document.
+	Path string `envconfig:"path" default:"/openapi.json"`
+	// UIEnable serves the embedded Swagger UI. Keep it off in production.
+	UIEnable bool   `envconfig:"ui_enable" default:"false"`
+	UIPath   string `envconfig:"ui_path" default:"/docs"`
+}
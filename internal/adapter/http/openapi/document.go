@@ -0,0 +1,75 @@
+package openapi
+
+// Version is the OpenAPI specification version of generated documents.
+const Version = "3.1.0"
+
+type (
+	// Document is an OpenAPI 3.1 document.
+	Document struct {
+		OpenAPI    string               `json:"openapi"`
+		Info       Info                 `json:"info"`
+		Paths      map[string]*PathItem `json:"paths"`
+		Components Components           `json:"components"`
+	}
+
+	// Info describes the API.
+	Info struct {
+		Title       string `json:"title"`
+		Description string `json:"description,omitempty"`
+		Version     string `json:"version"`
+	}
+
+	// PathItem holds the operations of a single path, keyed by lower case method.
+	PathItem map[string]*Operation
+
+	// Operation describes a single API operation on a path.
+	Operation struct {
+		OperationID string                `json:"operationId,omitempty"`
+		Summary     string                `json:"summary,omitempty"`
+		Description string                `json:"description,omitempty"`
+		Tags        []string              `json:"tags,omitempty"`
+		Parameters  []Parameter           `json:"parameters,omitempty"`
+		RequestBody *RequestBody          `json:"requestBody,omitempty"`
+		Responses   map[string]Response   `json:"responses"`
+		Security    []map[string][]string `json:"security,omitempty"`
+	}
+
+	// Parameter describes a path, query or header parameter.
+	Parameter struct {
+		Name     string  `json:"name"`
+		In       string  `json:"in"`
+		Required bool    `json:"required,omitempty"`
+		Schema   *Schema `json:"schema"`
+	}
+
+	// RequestBody describes the request payload.
+	RequestBody struct {
+		Required bool                 `json:"required"`
+		Content  map[string]MediaType `json:"content"`
+	}
+
+	// Response describes a single response.
+	Response struct {
+		Description string               `json:"description"`
+		Content     map[string]MediaType `json:"content,omitempty"`
+	}
+
+	// MediaType holds the schema of a payload.
+	MediaType struct {
+		Schema *Schema `json:"schema"`
+	}
+
+	// Components holds reusable schemas and security schemes.
+	Components struct {
+		Schemas         map[string]*Schema        `json:"schemas,omitempty"`
+		SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+	}
+
+	// SecurityScheme describes an authentication method.
+	SecurityScheme struct {
+		Type        string `json:"type"`
+		In          string `json:"in,omitempty"`
+		Name        string `json:"name,omitempty"`
+		Description string `json:"description,omitempty"`
+	}
+)
@@ -0,0 +1,58 @@
+package openapi
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/filesystem"
+)
+
+// swaggerUIAssets holds the pinned Swagger UI bundle, so the UI loads
+// nothing from third party CDNs.
+//
+//go:embed swagger-ui
+var swaggerUIAssets embed.FS
+
+//go:embed ui.html
+var uiHTML string
+
+var uiTemplate = template.Must(template.New("ui").Parse(uiHTML))
+
+// ServeDocument serves the OpenAPI document and, unless disabled, the documentation UI.
+func ServeDocument(app *fiber.App, registry *Registry, cfg Config) error {
+	app.Get(cfg.Path, func(ctx *fiber.Ctx) error {
+		return ctx.JSON(registry.Document())
+	})
+
+	if !cfg.UIEnable {
+		return nil
+	}
+
+	assetsURL := strings.TrimSuffix(cfg.UIPath, "/") + "/assets"
+
+	var page bytes.Buffer
+	err := uiTemplate.Execute(&page, map[string]string{
+		"Title":     cfg.Title,
+		"AssetsURL": assetsURL,
+		"SpecURL":   cfg.Path,
+	})
+	if err != nil {
+		return err
+	}
+
+	app.Get(cfg.UIPath, func(ctx *fiber.Ctx) error {
+		ctx.Type("html", "utf-8")
+		return ctx.Send(page.Bytes())
+	})
+	app.Use(assetsURL, filesystem.New(filesystem.Config{
+		Root:       http.FS(swaggerUIAssets),
+		PathPrefix: "swagger-ui",
+		MaxAge:     86400,
+	}))
+
+	return nil
+}
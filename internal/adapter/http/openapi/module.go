@@ -0,0 +1,19 @@
+package openapi
+
+import (
+	"github.com/widnyana/wasabi/internal/adapter/cli"
+	"go.uber.org/fx"
+)
+
+var (
+	Module = fx.Module("openapi", Providers, Invokers)
+
+	Providers = fx.Options(
+		fx.Provide(NewRegistry),
+		fx.Provide(cli.AsCommand(NewCommand)),
+	)
+
+	Invokers = fx.Options(
+		fx.Invoke(ServeDocument),
+	)
+)
@@ -0,0 +1,278 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/widnyana/wasabi/internal/adapter/buildinfo"
+)
+
+const (
+	securitySchemeAPIKey = "apiKey"
+	contentTypeJSON      = fiber.MIMEApplicationJSON
+)
+
+var pathParamPattern = regexp.MustCompile(`:([A-Za-z0-9_]+)[?+*]?`)
+
+type (
+	// Route is a fiber route together with the metadata describing it.
+	Route struct {
+		Method      string
+		Path        string
+		Summary     string
+		Description string
+		Tags        []string
+		// Request is a value of the request type. Fields tagged `params`,
+		// `query` or `reqHeader` become parameters, the rest the JSON body.
+		Request any
+		// Response is a value of the success response body type.
+		Response any
+		// Status is the success status code, 200 when zero.
+		Status int
+		// Auth marks the route as requiring an authenticated principal,
+		// optionally with the given Scopes.
+		Auth   bool
+		Scopes []string
+		// Errors lists the error status codes the route may return.
+		Errors   []int
+		Handlers []fiber.Handler
+	}
+
+	// Registry registers routes on fiber and keeps their metadata.
+	Registry struct {
+		cfg    Config
+		mu     sync.RWMutex
+		routes []Route
+	}
+)
+
+// NewRegistry creates a new Registry.
+func NewRegistry(cfg Config) *Registry {
+	return &Registry{cfg: cfg}
+}
+
+// Register adds the routes to router and records them for the document.
+// Router may be the app or a group; group prefixes are kept.
+func (registry *Registry) Register(router fiber.Router, routes ...Route) {
+	prefix := ""
+	if group, ok := router.(*fiber.Group); ok {
+		prefix = group.Prefix
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	for _, route := range routes {
+		router.Add(route.Method, route.Path, route.Handlers...)
+
+		route.Path = strings.TrimSuffix(prefix, "/") + route.Path
+		registry.routes = append(registry.routes, route)
+	}
+}
+
+// Document generates the OpenAPI document of all registered routes.
+func (registry *Registry) Document() *Document {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	gen := newSchemaGenerator()
+	doc := &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:       registry.cfg.Title,
+			Description: registry.cfg.Description,
+			Version:     buildinfo.Get().Version,
+		},
+		Paths: map[string]*PathItem{},
+	}
+
+	needsAuth := false
+	for _, route := range registry.routes {
+		path := pathParamPattern.ReplaceAllString(route.Path, "{$1}")
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+
+		(*item)[strings.ToLower(route.Method)] = operationOf(gen, route)
+		needsAuth = needsAuth || route.Auth
+	}
+
+	doc.Components.Schemas = gen.components
+	if needsAuth {
+		doc.Components.SecuritySchemes = map[string]SecurityScheme{
+			securitySchemeAPIKey: {
+				Type:        "apiKey",
+				In:          "header",
+				Name:        "X-API-Key",
+				Description: "API key, also accepted as `Authorization: ApiKey <key>`",
+			},
+		}
+	}
+
+	return doc
+}
+
+func operationOf(gen *schemaGenerator, route Route) *Operation {
+	op := &Operation{
+		OperationID: operationID(route),
+		Summary:     route.Summary,
+		Description: route.Description,
+		Tags:        route.Tags,
+		Parameters:  pathParameters(route.Path),
+		Responses:   map[string]Response{},
+	}
+
+	if route.Request != nil {
+		params, body := splitRequest(gen, reflect.TypeOf(route.Request))
+		op.Parameters = mergeParameters(op.Parameters, params)
+		if body != nil && route.Method != fiber.MethodGet && route.Method != fiber.MethodHead {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{contentTypeJSON: {Schema: body}},
+			}
+		}
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = fiber.StatusOK
+	}
+
+	success := Response{Description: http.StatusText(status)}
+	if route.Response != nil {
+		success.Content = map[string]MediaType{
+			contentTypeJSON: {Schema: gen.schemaOf(reflect.TypeOf(route.Response))},
+		}
+	}
+	op.Responses[strconv.Itoa(status)] = success
+
+	codes := append([]int{}, route.Errors...)
+	if route.Auth {
+		codes = append(codes, fiber.StatusUnauthorized)
+		if len(route.Scopes) > 0 {
+			codes = append(codes, fiber.StatusForbidden)
+		}
+		op.Security = []map[string][]string{{securitySchemeAPIKey: route.Scopes}}
+	}
+
+	// fiber's default error handler replies with the error message as text.
+	for _, code := range codes {
+		op.Responses[strconv.Itoa(code)] = Response{
+			Description: http.StatusText(code),
+			Content:     map[string]MediaType{fiber.MIMETextPlain: {Schema: &Schema{Type: "string"}}},
+		}
+	}
+
+	return op
+}
+
+// splitRequest separates parameter fields from body fields of a request type.
+func splitRequest(gen *schemaGenerator, t reflect.Type) ([]Parameter, *Schema) {
+	t = derefType(t)
+	if t.Kind() != reflect.Struct {
+		return nil, gen.schemaOf(t)
+	}
+
+	var params []Parameter
+	body := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if name, in, ok := parameterTag(field); ok {
+			params = append(params, Parameter{
+				Name:     name,
+				In:       in,
+				Required: in == "path",
+				Schema:   gen.schemaOf(field.Type),
+			})
+			continue
+		}
+
+		name, omitempty, skip := jsonName(field)
+		if skip {
+			continue
+		}
+
+		body.Properties[name] = gen.schemaOf(field.Type)
+		if !omitempty && field.Type.Kind() != reflect.Pointer {
+			body.Required = append(body.Required, name)
+		}
+	}
+
+	if len(body.Properties) == 0 {
+		return params, nil
+	}
+
+	return params, body
+}
+
+// parameterTag reads the fiber parser tags of a field.
+func parameterTag(field reflect.StructField) (name, in string, ok bool) {
+	for tag, location := range map[string]string{"params": "path", "query": "query", "reqHeader": "header"} {
+		if value, found := field.Tag.Lookup(tag); found {
+			name, _, _ = strings.Cut(value, ",")
+			return name, location, true
+		}
+	}
+
+	return "", "", false
+}
+
+func pathParameters(path string) []Parameter {
+	var params []Parameter
+	for _, match := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+		params = append(params, Parameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+
+	return params
+}
+
+// mergeParameters lets typed parameters override the ones inferred from the path.
+func mergeParameters(inferred, typed []Parameter) []Parameter {
+	merged := map[string]Parameter{}
+	for _, param := range append(inferred, typed...) {
+		merged[param.In+":"+param.Name] = param
+	}
+
+	result := make([]Parameter, 0, len(merged))
+	for _, param := range merged {
+		result = append(result, param)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].In != result[j].In {
+			return result[i].In < result[j].In
+		}
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
+
+func operationID(route Route) string {
+	parts := []string{strings.ToLower(route.Method)}
+	for _, segment := range strings.Split(route.Path, "/") {
+		segment = strings.Trim(pathParamPattern.ReplaceAllString(segment, "by_$1"), "{}")
+		if segment != "" {
+			parts = append(parts, segment)
+		}
+	}
+
+	return strings.Join(parts, "_")
+}
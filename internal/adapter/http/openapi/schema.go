@@ -0,0 +1,169 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema is a JSON Schema (draft 2020-12) as used by OpenAPI 3.1.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	byteSliceType  = reflect.TypeOf([]byte{})
+)
+
+// schemaGenerator derives schemas from Go types, collecting named
+// structs as reusable components.
+type schemaGenerator struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{
+		components: map[string]*Schema{},
+		names:      map[reflect.Type]string{},
+	}
+}
+
+func (gen *schemaGenerator) schemaOf(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{}
+	case byteSliceType:
+		return &Schema{Type: "string", Format: "byte"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(gen.schemaOf(t.Elem()))
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: gen.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: gen.schemaOf(t.Elem())}
+	case reflect.Struct:
+		return gen.structRef(t)
+	default:
+		// Interfaces, funcs and channels accept anything.
+		return &Schema{}
+	}
+}
+
+// structRef returns a $ref to the component of a named struct,
+// or the inline schema of an anonymous one.
+func (gen *schemaGenerator) structRef(t reflect.Type) *Schema {
+	if t.Name() == "" {
+		return gen.structSchema(t)
+	}
+
+	name, ok := gen.names[t]
+	if !ok {
+		name = gen.componentName(t)
+		gen.names[t] = name
+		// Register before descending so recursive types terminate.
+		gen.components[name] = &Schema{}
+		*gen.components[name] = *gen.structSchema(t)
+	}
+
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (gen *schemaGenerator) componentName(t reflect.Type) string {
+	name := t.Name()
+	if _, taken := gen.components[name]; !taken {
+		return name
+	}
+
+	pkg := t.PkgPath()
+	return pkg[strings.LastIndex(pkg, "/")+1:] + "." + name
+}
+
+func (gen *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	gen.addFields(schema, t)
+	return schema
+}
+
+func (gen *schemaGenerator) addFields(schema *Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, omitempty, skip := jsonName(field)
+		if skip {
+			continue
+		}
+
+		if field.Anonymous && field.Tag.Get("json") == "" && derefType(field.Type).Kind() == reflect.Struct {
+			gen.addFields(schema, derefType(field.Type))
+			continue
+		}
+
+		schema.Properties[name] = gen.schemaOf(field.Type)
+		if !omitempty && field.Type.Kind() != reflect.Pointer {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// jsonName mirrors encoding/json's handling of the json struct tag.
+func jsonName(field reflect.StructField) (name string, omitempty, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+
+	return name, strings.Contains(","+opts+",", ",omitempty,"), false
+}
+
+func nullable(schema *Schema) *Schema {
+	if typ, ok := schema.Type.(string); ok {
+		clone := *schema
+		clone.Type = []string{typ, "null"}
+		return &clone
+	}
+
+	return &Schema{OneOf: []*Schema{schema, {Type: "null"}}}
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"slices"
+
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+type (
+	// ProbeChain is the ordered list of enabled probes, mounted on the app
+	// one after another.
+	ProbeChain []Probe
+
+	// ProbeParams holds the probes collected from the "probes" value group.
+	ProbeParams struct {
+		fx.In
+
+		Config Config
+		Logger *otelzap.Logger
+		Probes []Probe `group:"probes"`
+	}
+)
+
+// AsProbe annotates a constructor so its result joins the "probes" value group.
+//
+//	fx.Provide(http.AsProbe(NewAuditProbe))
+func AsProbe(constructor any) any {
+	return fx.Annotate(constructor, fx.As(new(Probe)), fx.ResultTags(`group:"probes"`))
+}
+
+// NewProbeChain drops the probes disabled in config and orders the rest.
+// Probes listed in ProbeConfig.Order come first, in that order; the others
+// follow sorted by name, as fx does not guarantee group order.
+func NewProbeChain(params ProbeParams) ProbeChain {
+	cfg := params.Config.Probe
+
+	chain := make(ProbeChain, 0, len(params.Probes))
+	for _, probe := range params.Probes {
+		if slices.Contains(cfg.Disabled, probe.Name()) {
+			params.Logger.Debug("http probe disabled", zap.String("probe", probe.Name()))
+			continue
+		}
+		chain = append(chain, probe)
+	}
+
+	rank := func(probe Probe) int {
+		if i := slices.Index(cfg.Order, probe.Name()); i >= 0 {
+			return i
+		}
+		return len(cfg.Order)
+	}
+
+	slices.SortStableFunc(chain, func(a, b Probe) int {
+		if ra, rb := rank(a), rank(b); ra != rb {
+			return ra - rb
+		}
+		if a.Name() < b.Name() {
+			return -1
+		}
+		if a.Name() > b.Name() {
+			return 1
+		}
+		return 0
+	})
+
+	return chain
+}
@@ -1,7 +1,9 @@
 package http
 
 import (
+	"errors"
 	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/prometheus/client_golang/prometheus"
@@ -9,46 +11,158 @@ import (
 )
 
 const (
-	lblIP     = "ip"
-	lblMethod = "method"
-	lblPath   = "path"
-	lblStatus = "status"
+	lblMethod      = "method"
+	lblRoute       = "route"
+	lblStatusClass = "status_class"
+
+	// routeUnmatched is the route label of requests that matched no route,
+	// so scanners probing random paths can't blow up cardinality.
+	routeUnmatched = "unmatched"
+	methodOther    = "OTHER"
+	methodUse      = "USE"
+
+	promProbeName = "prometheus"
 )
 
+var sizeObjectives = map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001}
+
 // PromProbe is a probe that collects metrics for HTTP requests.
+// All labels are bounded: method, route template and status class.
 type PromProbe struct {
-	req *prometheus.CounterVec
+	req      *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight prometheus.Gauge
+	reqSize  *prometheus.SummaryVec
+	respSize *prometheus.SummaryVec
 }
 
-type PromHabitProbe struct{}
+// NewPromProbe creates a new PromProbe registering its metrics into registerer.
+func NewPromProbe(config Config, registerer prometheus.Registerer) *PromProbe {
+	cfg := config.Probe
+	factory := promauto.With(registerer)
+	labels := []string{lblMethod, lblRoute, lblStatusClass}
+
+	buckets := cfg.DurationBuckets
+	if len(buckets) == 0 {
+		buckets = prometheus.DefBuckets
+	}
 
-// NewPromProbe creates a new PromProbe.
-func NewPromProbe() *PromProbe {
 	return &PromProbe{
-		req: promauto.NewCounterVec(
+		req: factory.NewCounterVec(
 			prometheus.CounterOpts{
-				Name: "app_request_total",
-				Help: "Total number of application requests",
+				Name:        "app_request_total",
+				Help:        "Total number of application requests",
+				ConstLabels: cfg.ConstLabels,
+			},
+			labels,
+		),
+		duration: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:        "app_request_duration_seconds",
+				Help:        "Duration of application requests in seconds",
+				ConstLabels: cfg.ConstLabels,
+				Buckets:     buckets,
+			},
+			labels,
+		),
+		inFlight: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name:        "app_requests_in_flight",
+				Help:        "Number of application requests currently being served",
+				ConstLabels: cfg.ConstLabels,
+			},
+		),
+		reqSize: factory.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Name:        "app_request_size_bytes",
+				Help:        "Size of application request bodies in bytes",
+				ConstLabels: cfg.ConstLabels,
+				Objectives:  sizeObjectives,
+			},
+			labels,
+		),
+		respSize: factory.NewSummaryVec(
+			prometheus.SummaryOpts{
+				Name:        "app_response_size_bytes",
+				Help:        "Size of application response bodies in bytes",
+				ConstLabels: cfg.ConstLabels,
+				Objectives:  sizeObjectives,
 			},
-			[]string{lblIP, lblMethod, lblPath, lblStatus},
+			labels,
 		),
 	}
 }
 
-// Middleware is a middleware that logs HTTP requests.
-func (probe *PromProbe) Middleware(ctx *fiber.Ctx) error {
-	defer probe.LogReq(ctx)
+// Name implements Probe.
+func (probe *PromProbe) Name() string { return promProbeName }
+
+// Middleware is a middleware that records metrics of HTTP requests.
+func (probe *PromProbe) Middleware(ctx *fiber.Ctx) (err error) {
+	probe.inFlight.Inc()
+	defer probe.inFlight.Dec()
+
+	// The request is recorded from a deferred func so that a panicking
+	// handler, recovered further out, still counts as the 500 it becomes.
+	start := time.Now()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			probe.LogReq(ctx, fiber.ErrInternalServerError, time.Since(start).Seconds())
+			panic(recovered)
+		}
+		probe.LogReq(ctx, err, time.Since(start).Seconds())
+	}()
+
 	return ctx.Next()
 }
 
-// LogReq logs an HTTP request.
-func (probe *PromProbe) LogReq(ctx *fiber.Ctx) {
+// LogReq records an HTTP request. The handler error is used to determine
+// the status, as the error handler only writes it after the middleware chain.
+func (probe *PromProbe) LogReq(ctx *fiber.Ctx, err error, seconds float64) {
 	labels := prometheus.Labels{
-		lblIP:     ctx.IP(),
-		lblMethod: ctx.Route().Method,
-		lblPath:   ctx.Route().Path,
-		lblStatus: strconv.Itoa(ctx.Response().StatusCode()),
+		lblMethod:      methodLabel(ctx.Method()),
+		lblRoute:       routeLabel(ctx),
+		lblStatusClass: statusClass(statusOf(ctx, err)),
 	}
 
 	probe.req.With(labels).Inc()
+	probe.duration.With(labels).Observe(seconds)
+	probe.reqSize.With(labels).Observe(float64(len(ctx.Request().Body())))
+	probe.respSize.With(labels).Observe(float64(len(ctx.Response().Body())))
+}
+
+func routeLabel(ctx *fiber.Ctx) string {
+	route := ctx.Route()
+	// Only middleware matched, no handler was registered for the path.
+	if route.Method == methodUse || route.Path == "" {
+		return routeUnmatched
+	}
+
+	return route.Path
+}
+
+func methodLabel(method string) string {
+	switch method {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch,
+		fiber.MethodDelete, fiber.MethodConnect, fiber.MethodOptions, fiber.MethodTrace:
+		return method
+	default:
+		return methodOther
+	}
+}
+
+func statusOf(ctx *fiber.Ctx, err error) int {
+	if err == nil {
+		return ctx.Response().StatusCode()
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code
+	}
+
+	return fiber.StatusInternalServerError
+}
+
+func statusClass(status int) string {
+	return strconv.Itoa(status/100) + "xx"
 }
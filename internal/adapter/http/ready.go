@@ -0,0 +1,23 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/widnyana/wasabi/internal/adapter/health"
+)
+
+// ServeReadiness mounts the readiness endpoint on the public port, so
+// orchestrators can reach it without the admin server. It only answers the
+// overall status; the per check report stays on the admin /health.
+func ServeReadiness(app *fiber.App, config Config, registry *health.Registry) {
+	if config.ReadinessPath == "" {
+		return
+	}
+
+	app.Get(config.ReadinessPath, func(ctx *fiber.Ctx) error {
+		report := registry.Report(ctx.UserContext())
+		if report.Status != health.StatusUp {
+			ctx.Status(fiber.StatusServiceUnavailable)
+		}
+		return ctx.JSON(fiber.Map{"status": report.Status})
+	})
+}
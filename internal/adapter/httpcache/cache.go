@@ -0,0 +1,283 @@
+package httpcache
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/gofiber/fiber/v2"
+	grds "github.com/redis/go-redis/v9"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"github.com/widnyana/wasabi/internal/adapter/database/pg"
+	applog "github.com/widnyana/wasabi/internal/adapter/logger"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const (
+	entryKeyPrefix = "wasabi:httpcache:entry:"
+	tagKeyPrefix   = "wasabi:httpcache:tag:"
+
+	// HeaderCacheStatus reports whether the response was served from cache.
+	HeaderCacheStatus = "X-Cache"
+
+	localsTags = "httpcache.tags"
+)
+
+// tagScript adds an entry to a tag set and extends the set's expiry to the
+// entry's TTL, never shortening it. It stands in for EXPIRE GT/NX, which
+// need Redis 7.
+var tagScript = grds.NewScript(`
+redis.call("SADD", KEYS[1], ARGV[1])
+if redis.call("PTTL", KEYS[1]) < tonumber(ARGV[2]) then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+type (
+	// Cache stores HTTP responses in Redis. Without Redis the middleware
+	// passes every request through, so routes can opt in unconditionally.
+	Cache struct {
+		cfg    Config
+		client *grds.Client
+		logger *otelzap.Logger
+	}
+
+	// Params holds the dependencies of Cache.
+	Params struct {
+		fx.In
+
+		Config Config
+		Logger *otelzap.Logger
+		Redis  *grds.Client `optional:"true"`
+	}
+
+	// Option customizes caching of a single route.
+	Option func(*routeOptions)
+
+	routeOptions struct {
+		ttl  time.Duration
+		vary []string
+		tags func(*fiber.Ctx) []string
+	}
+)
+
+// NewCache creates a new Cache.
+func NewCache(params Params) *Cache {
+	return &Cache{cfg: params.Config, client: params.Redis, logger: params.Logger}
+}
+
+// TTL overrides the default TTL of the route.
+func TTL(ttl time.Duration) Option {
+	return func(opts *routeOptions) { opts.ttl = ttl }
+}
+
+// Vary adds request headers to the cache key of the route.
+func Vary(headers ...string) Option {
+	return func(opts *routeOptions) { opts.vary = append(opts.vary, headers...) }
+}
+
+// Tags attaches static invalidation tags to the route's entries.
+func Tags(tags ...string) Option {
+	return TagsFunc(func(*fiber.Ctx) []string { return tags })
+}
+
+// TagsFunc attaches invalidation tags computed from the request.
+func TagsFunc(fn func(*fiber.Ctx) []string) Option {
+	return func(opts *routeOptions) { opts.tags = fn }
+}
+
+// AddTags attaches invalidation tags from inside a handler, e.g. once the
+// loaded entity is known.
+func AddTags(ctx *fiber.Ctx, tags ...string) {
+	existing, _ := ctx.Locals(localsTags).([]string)
+	ctx.Locals(localsTags, append(existing, tags...))
+}
+
+// Route returns a middleware that caches the responses of the route it is mounted on.
+// On authenticated routes mount it after the auth middleware: entries are
+// then kept per principal, while requests carrying credentials that were not
+// authenticated yet bypass the cache.
+//
+//	app.Get("/products/:id", cache.Route(httpcache.TTL(time.Minute), httpcache.Tags("products")), getProduct)
+func (cache *Cache) Route(options ...Option) fiber.Handler {
+	opts := routeOptions{ttl: cache.cfg.DefaultTTL}
+	for _, option := range options {
+		option(&opts)
+	}
+
+	vary := append(append([]string{}, cache.cfg.Vary...), opts.vary...)
+
+	return func(ctx *fiber.Ctx) error {
+		if cache.client == nil || (ctx.Method() != fiber.MethodGet && ctx.Method() != fiber.MethodHead) {
+			return ctx.Next()
+		}
+
+		reqCC := parseCacheControl(ctx.Get(fiber.HeaderCacheControl))
+		if reqCC.noStore || unauthenticatedCredentials(ctx) {
+			return ctx.Next()
+		}
+
+		userCtx := ctx.UserContext()
+		key := cacheKey(ctx, vary)
+
+		if !reqCC.noCache {
+			if cached, ok := cache.load(userCtx, key); ok {
+				return serve(ctx, cached)
+			}
+		}
+
+		// The response is about to be cached, so the handler reads from the
+		// primary: a lagging replica would put data invalidated moments ago
+		// back into the cache.
+		ctx.SetUserContext(pg.WithPrimary(userCtx))
+		if err := ctx.Next(); err != nil {
+			return err
+		}
+
+		return cache.store(ctx, key, opts)
+	}
+}
+
+// Invalidate drops every entry tagged with any of the tags.
+func (cache *Cache) Invalidate(ctx context.Context, tags ...string) error {
+	if cache.client == nil || len(tags) == 0 {
+		return nil
+	}
+
+	var keys []string
+	for _, tag := range tags {
+		members, err := cache.client.SMembers(ctx, tagKeyPrefix+tag).Result()
+		if err != nil && !errors.Is(err, grds.Nil) {
+			return err
+		}
+
+		keys = append(keys, tagKeyPrefix+tag)
+		for _, member := range members {
+			keys = append(keys, entryKeyPrefix+member)
+		}
+	}
+
+	return cache.client.Del(ctx, keys...).Err()
+}
+
+func (cache *Cache) load(ctx context.Context, key string) (*entry, bool) {
+	raw, err := cache.client.Get(ctx, entryKeyPrefix+key).Bytes()
+	if err != nil {
+		if !errors.Is(err, grds.Nil) {
+			applog.Ctx(cache.logger, ctx).Warn("failed to read cached response", zap.Error(err))
+		}
+		return nil, false
+	}
+
+	var cached entry
+	if err := sonic.Unmarshal(raw, &cached); err != nil {
+		return nil, false
+	}
+
+	return &cached, true
+}
+
+func (cache *Cache) store(ctx *fiber.Ctx, key string, opts routeOptions) error {
+	resp := ctx.Response()
+	if resp.StatusCode() != fiber.StatusOK {
+		return nil
+	}
+
+	body := resp.Body()
+	etag := string(resp.Header.Peek(fiber.HeaderETag))
+	if etag == "" {
+		etag = etagOf(body)
+		ctx.Set(fiber.HeaderETag, etag)
+	}
+
+	lastModified := time.Now().UTC()
+	if lm, err := http.ParseTime(string(resp.Header.Peek(fiber.HeaderLastModified))); err == nil {
+		lastModified = lm
+	} else {
+		ctx.Set(fiber.HeaderLastModified, lastModified.Format(http.TimeFormat))
+	}
+
+	ttl := opts.ttl
+	respCC := parseCacheControl(string(resp.Header.Peek(fiber.HeaderCacheControl)))
+	if respCC.hasAge {
+		ttl = respCC.maxAge
+	}
+
+	cacheable := !respCC.noStore && !respCC.private && ttl > 0 &&
+		(cache.cfg.MaxBodyBytes <= 0 || len(body) <= cache.cfg.MaxBodyBytes)
+	if !cacheable {
+		return nil
+	}
+
+	cached := entry{
+		StatusCode:   resp.StatusCode(),
+		Headers:      map[string]string{},
+		Body:         append([]byte(nil), body...),
+		ETag:         etag,
+		LastModified: lastModified,
+		StoredAt:     time.Now().UTC(),
+	}
+	resp.Header.VisitAll(func(name, value []byte) {
+		switch string(name) {
+		case fiber.HeaderContentLength, fiber.HeaderDate, fiber.HeaderSetCookie, fiber.HeaderConnection:
+		default:
+			cached.Headers[string(name)] = string(value)
+		}
+	})
+
+	var tags []string
+	if opts.tags != nil {
+		tags = append(tags, opts.tags(ctx)...)
+	}
+	if extra, ok := ctx.Locals(localsTags).([]string); ok {
+		tags = append(tags, extra...)
+	}
+
+	if err := cache.write(ctx.UserContext(), key, cached, ttl, tags); err != nil {
+		applog.Ctx(cache.logger, ctx.UserContext()).Warn("failed to cache response", zap.Error(err))
+	}
+
+	ctx.Set(HeaderCacheStatus, "MISS")
+	if notModified(ctx, etag, lastModified) {
+		ctx.Status(fiber.StatusNotModified).Response().ResetBody()
+	}
+
+	return nil
+}
+
+func (cache *Cache) write(ctx context.Context, key string, cached entry, ttl time.Duration, tags []string) error {
+	raw, err := sonic.Marshal(cached)
+	if err != nil {
+		return err
+	}
+
+	_, err = cache.client.TxPipelined(ctx, func(pipe grds.Pipeliner) error {
+		pipe.Set(ctx, entryKeyPrefix+key, raw, ttl)
+		for _, tag := range tags {
+			// Tag sets only need to outlive their longest entry.
+			tagScript.Eval(ctx, pipe, []string{tagKeyPrefix + tag}, key, ttl.Milliseconds())
+		}
+		return nil
+	})
+
+	return err
+}
+
+func serve(ctx *fiber.Ctx, cached *entry) error {
+	for name, value := range cached.Headers {
+		ctx.Set(name, value)
+	}
+	ctx.Set(HeaderCacheStatus, "HIT")
+	ctx.Set(fiber.HeaderAge, strconv.Itoa(int(time.Since(cached.StoredAt).Seconds())))
+
+	if notModified(ctx, cached.ETag, cached.LastModified) {
+		return ctx.SendStatus(fiber.StatusNotModified)
+	}
+
+	return ctx.Status(cached.StatusCode).Send(cached.Body)
+}
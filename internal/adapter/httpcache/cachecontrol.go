@@ -0,0 +1,43 @@
+package httpcache
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cacheControl holds the directives this cache acts on.
+type cacheControl struct {
+	noStore bool
+	noCache bool
+	private bool
+	maxAge  time.Duration
+	hasAge  bool
+}
+
+func parseCacheControl(header string) cacheControl {
+	var cc cacheControl
+	for _, directive := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store":
+			cc.noStore = true
+		case "no-cache":
+			cc.noCache = true
+		case "private":
+			cc.private = true
+		case "max-age", "s-maxage":
+			seconds, err := strconv.Atoi(strings.Trim(value, `"`))
+			if err != nil {
+				continue
+			}
+			// s-maxage wins over max-age for shared caches.
+			if !cc.hasAge || strings.EqualFold(name, "s-maxage") {
+				cc.maxAge = time.Duration(seconds) * time.Second
+				cc.hasAge = true
+			}
+		}
+	}
+
+	return cc
+}
@@ -0,0 +1,13 @@
+package httpcache
+
+import "time"
+
+// Config represents the configuration for the HTTP response cache.
+type Config struct {
+	// DefaultTTL is used when neither the route nor the response sets a max-age.
+	DefaultTTL time.Duration `envconfig:"default_ttl" default:"1m"`
+	// MaxBodyBytes skips caching of larger responses.
+	MaxBodyBytes int `envconfig:"max_body_bytes" default:"1048576"`
+	// Vary lists request headers that are part of every cache key.
+	Vary []string `envconfig:"vary" default:"Accept,Accept-Language"`
+}
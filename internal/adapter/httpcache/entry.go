@@ -0,0 +1,103 @@
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/widnyana/wasabi/internal/adapter/auth"
+)
+
+// entry is a cached response.
+type entry struct {
+	StatusCode   int               `json:"status_code"`
+	Headers      map[string]string `json:"headers"`
+	Body         []byte            `json:"body"`
+	ETag         string            `json:"etag"`
+	LastModified time.Time         `json:"last_modified"`
+	StoredAt     time.Time         `json:"stored_at"`
+}
+
+// cacheKey derives the cache key from the method, path, normalized query,
+// the values of the vary headers and the authenticated principal, so one
+// client's responses are never served to another.
+func cacheKey(ctx *fiber.Ctx, vary []string) string {
+	hash := sha256.New()
+	if principal, ok := auth.PrincipalFromContext(ctx.UserContext()); ok {
+		hash.Write([]byte(principal.Kind + ":" + principal.ID))
+	}
+	hash.Write([]byte{0})
+	hash.Write([]byte(ctx.Method()))
+	hash.Write([]byte{0})
+	hash.Write([]byte(ctx.Path()))
+	hash.Write([]byte{0})
+	hash.Write([]byte(normalizeQuery(string(ctx.Request().URI().QueryString()))))
+
+	for _, header := range vary {
+		hash.Write([]byte{0})
+		hash.Write([]byte(strings.ToLower(header)))
+		hash.Write([]byte{'='})
+		hash.Write([]byte(ctx.Get(header)))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// unauthenticatedCredentials reports whether the request carries credentials
+// that no auth middleware has turned into a principal yet. Such responses
+// depend on a caller the cache key can't tell apart.
+func unauthenticatedCredentials(ctx *fiber.Ctx) bool {
+	if _, ok := auth.PrincipalFromContext(ctx.UserContext()); ok {
+		return false
+	}
+
+	return ctx.Get(fiber.HeaderAuthorization) != "" || ctx.Get(fiber.HeaderCookie) != ""
+}
+
+// normalizeQuery sorts parameters and their values so that equivalent
+// query strings map to the same key.
+func normalizeQuery(raw string) string {
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return raw
+	}
+
+	for name := range values {
+		sort.Strings(values[name])
+	}
+
+	// url.Values.Encode sorts by key.
+	return values.Encode()
+}
+
+// etagOf computes a strong ETag from the response body.
+func etagOf(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// notModified evaluates the conditional request headers against the entry.
+// If-None-Match takes precedence over If-Modified-Since, as per RFC 9110.
+func notModified(ctx *fiber.Ctx, etag string, lastModified time.Time) bool {
+	if inm := ctx.Get(fiber.HeaderIfNoneMatch); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := ctx.Get(fiber.HeaderIfModifiedSince); ims != "" {
+		since, err := http.ParseTime(ims)
+		return err == nil && !lastModified.Truncate(time.Second).After(since)
+	}
+
+	return false
+}
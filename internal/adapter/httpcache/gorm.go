@@ -0,0 +1,54 @@
+package httpcache
+
+import (
+	"context"
+
+	"github.com/widnyana/wasabi/internal/adapter/database/pg"
+	applog "github.com/widnyana/wasabi/internal/adapter/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const gormCallbackName = "httpcache:invalidate"
+
+// TableTag is the tag invalidated whenever GORM writes to the table.
+// Tag routes with it to have them refreshed after updates:
+//
+//	cache.Route(httpcache.Tags(httpcache.TableTag("products")))
+func TableTag(table string) string {
+	return "table:" + table
+}
+
+// RegisterGormInvalidation registers GORM callbacks that invalidate the
+// TableTag of a table after every successful create, update or delete.
+// Writes made inside a pg.TxManager transaction invalidate once it commits,
+// so readers can't cache the old rows again in between. A failed
+// invalidation is logged; the write itself has already been stored.
+func RegisterGormInvalidation(db *gorm.DB, cache *Cache) error {
+	invalidate := func(tx *gorm.DB) {
+		if tx.Error != nil || tx.RowsAffected == 0 || tx.Statement.Table == "" {
+			return
+		}
+
+		tag := TableTag(tx.Statement.Table)
+		run := func(ctx context.Context) {
+			if err := cache.Invalidate(ctx, tag); err != nil {
+				applog.Ctx(cache.logger, ctx).Warn("failed to invalidate cached responses", zap.String("tag", tag), zap.Error(err))
+			}
+		}
+
+		if !pg.AfterCommit(tx.Statement.Context, run) {
+			run(tx.Statement.Context)
+		}
+	}
+
+	callbacks := db.Callback()
+	if err := callbacks.Create().After("gorm:create").Register(gormCallbackName, invalidate); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:update").Register(gormCallbackName, invalidate); err != nil {
+		return err
+	}
+
+	return callbacks.Delete().After("gorm:delete").Register(gormCallbackName, invalidate)
+}
@@ -0,0 +1,36 @@
+package httpcache
+
+import (
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+var (
+	Module = fx.Module("httpcache", Providers, Invokers)
+
+	Providers = fx.Options(
+		fx.Provide(NewCache),
+	)
+
+	Invokers = fx.Options(
+		fx.Invoke(EnableGormInvalidation),
+	)
+)
+
+// GormParams holds the optional GORM connection used for invalidation.
+type GormParams struct {
+	fx.In
+
+	DB    *gorm.DB `optional:"true"`
+	Cache *Cache
+}
+
+// EnableGormInvalidation invalidates table tags after GORM writes
+// when both Redis and Postgres are available.
+func EnableGormInvalidation(params GormParams) error {
+	if params.DB == nil || params.Cache.client == nil {
+		return nil
+	}
+
+	return RegisterGormInvalidation(params.DB, params.Cache)
+}
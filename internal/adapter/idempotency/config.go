@@ -0,0 +1,13 @@
+package idempotency
+
+import "time"
+
+// Config represents the configuration for the idempotency middleware.
+type Config struct {
+	// ResponseTTL is how long a completed response is kept for replay.
+	ResponseTTL time.Duration `envconfig:"response_ttl" default:"24h"`
+	// LockTTL bounds how long a request may stay in flight before its key is released.
+	LockTTL time.Duration `envconfig:"lock_ttl" default:"1m"`
+	// MaxKeyLength rejects unreasonably long Idempotency-Key headers.
+	MaxKeyLength int `envconfig:"max_key_length" default:"255"`
+}
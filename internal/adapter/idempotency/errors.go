@@ -0,0 +1,9 @@
+package idempotency
+
+import "errors"
+
+var (
+	ErrNoStore     = errors.New("idempotency requires either redis or postgres")
+	ErrKeyNotFound = errors.New("idempotency key not found")
+	ErrKeyNotHeld  = errors.New("idempotency key is no longer held by this request")
+)
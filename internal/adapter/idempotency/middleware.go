@@ -0,0 +1,171 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"github.com/widnyana/wasabi/internal/adapter/auth"
+	applog "github.com/widnyana/wasabi/internal/adapter/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// HeaderIdempotencyKey is the request header carrying the client supplied key.
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderReplayed is set on responses replayed from the store.
+	HeaderReplayed = "Idempotent-Replayed"
+)
+
+// skippedHeaders are recomputed by fasthttp and must not be replayed.
+var skippedHeaders = map[string]struct{}{
+	fiber.HeaderContentLength:    {},
+	fiber.HeaderDate:             {},
+	fiber.HeaderConnection:       {},
+	fiber.HeaderTransferEncoding: {},
+	fiber.HeaderSetCookie:        {},
+}
+
+// Middleware makes unsafe requests carrying an Idempotency-Key safe to retry.
+type Middleware struct {
+	cfg    Config
+	store  Store
+	logger *otelzap.Logger
+}
+
+// NewMiddleware creates a new Middleware.
+func NewMiddleware(cfg Config, store Store, logger *otelzap.Logger) *Middleware {
+	return &Middleware{cfg: cfg, store: store, logger: logger}
+}
+
+// Handle is the fiber handler. Mount it on the routes that should honor
+// Idempotency-Key, e.g. app.Post("/orders", idem.Handle, createOrder).
+//
+//   - the first request reserves the key and its response is stored;
+//   - a concurrent duplicate gets 409 Conflict while the first is in flight;
+//   - a repeat gets the stored response replayed;
+//   - a different payload under the same key gets 422 Unprocessable Entity.
+//
+// Errors, panics and 5xx responses release the key so the client may retry.
+func (mw *Middleware) Handle(ctx *fiber.Ctx) error {
+	if isSafeMethod(ctx.Method()) {
+		return ctx.Next()
+	}
+
+	key := strings.TrimSpace(ctx.Get(HeaderIdempotencyKey))
+	if key == "" {
+		return ctx.Next()
+	}
+
+	if mw.cfg.MaxKeyLength > 0 && len(key) > mw.cfg.MaxKeyLength {
+		return fiber.NewError(fiber.StatusBadRequest, "idempotency key is too long")
+	}
+
+	userCtx := ctx.UserContext()
+	storeKey := scopedKey(userCtx, key)
+	fingerprint := requestFingerprint(ctx)
+
+	record, acquired, err := mw.store.Begin(userCtx, storeKey, fingerprint, mw.cfg.LockTTL)
+	if err != nil {
+		return err
+	}
+
+	if !acquired {
+		return replay(ctx, record, fingerprint)
+	}
+
+	// The key is released unless the response gets stored, including when
+	// the handler panics, so the client may retry.
+	stored := false
+	defer func() {
+		if !stored {
+			mw.release(userCtx, record)
+		}
+	}()
+
+	if err := ctx.Next(); err != nil {
+		return err
+	}
+
+	if ctx.Response().StatusCode() >= fiber.StatusInternalServerError {
+		return nil
+	}
+
+	stored = true
+	if err := mw.store.Complete(userCtx, record, captureResponse(ctx), mw.cfg.ResponseTTL); err != nil {
+		applog.Ctx(mw.logger, userCtx).Error("failed to store idempotent response", zap.String("key", key), zap.Error(err))
+	}
+
+	return nil
+}
+
+func (mw *Middleware) release(ctx context.Context, record *Record) {
+	if err := mw.store.Release(ctx, record); err != nil {
+		applog.Ctx(mw.logger, ctx).Warn("failed to release idempotency key", zap.String("key", record.Key), zap.Error(err))
+	}
+}
+
+func replay(ctx *fiber.Ctx, record *Record, fingerprint string) error {
+	if record.Fingerprint != fingerprint {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, "idempotency key was already used with a different request")
+	}
+
+	if !record.Completed {
+		return fiber.NewError(fiber.StatusConflict, "a request with this idempotency key is still in progress")
+	}
+
+	for name, value := range record.Headers {
+		ctx.Set(name, value)
+	}
+	ctx.Set(HeaderReplayed, "true")
+
+	return ctx.Status(record.StatusCode).Send(record.Body)
+}
+
+func captureResponse(ctx *fiber.Ctx) Response {
+	headers := map[string]string{}
+	ctx.Response().Header.VisitAll(func(name, value []byte) {
+		if _, skip := skippedHeaders[string(name)]; !skip {
+			headers[string(name)] = string(value)
+		}
+	})
+
+	return Response{
+		StatusCode: ctx.Response().StatusCode(),
+		Headers:    headers,
+		Body:       append([]byte(nil), ctx.Response().Body()...),
+	}
+}
+
+// requestFingerprint identifies the payload a key was first used with.
+func requestFingerprint(ctx *fiber.Ctx) string {
+	hash := sha256.New()
+	hash.Write([]byte(ctx.Method()))
+	hash.Write([]byte{0})
+	hash.Write([]byte(ctx.OriginalURL()))
+	hash.Write([]byte{0})
+	hash.Write(ctx.Body())
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// scopedKey namespaces the key by the authenticated principal so
+// different clients can't collide on the same key.
+func scopedKey(ctx context.Context, key string) string {
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		return principal.Kind + ":" + principal.ID + ":" + key
+	}
+
+	return "anonymous:" + key
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions, fiber.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,14 @@
+package idempotency
+
+import "go.uber.org/fx"
+
+// Module provides the idempotency middleware. The idempotency_keys table
+// of the Postgres store is created by the migrations of the migrate package.
+var (
+	Module = fx.Module("idempotency", Providers)
+
+	Providers = fx.Options(
+		fx.Provide(NewStore),
+		fx.Provide(NewMiddleware),
+	)
+)
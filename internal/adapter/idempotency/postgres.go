@@ -0,0 +1,88 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/widnyana/wasabi/internal/adapter/database/pg"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PostgresStore keeps idempotency records in Postgres.
+// It is used when Redis is disabled.
+type PostgresStore struct {
+	db *gorm.DB
+}
+
+// NewPostgresStore creates a new PostgresStore.
+func NewPostgresStore(db *gorm.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// Begin implements Store.
+func (store *PostgresStore) Begin(
+	ctx context.Context,
+	key, fingerprint string,
+	lockTTL time.Duration,
+) (*Record, bool, error) {
+	record, err := newRecord(key, fingerprint, lockTTL)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Expired records are treated as absent. The existing record is read
+	// back right after the insert, so everything goes to the primary.
+	db := store.db.WithContext(pg.WithPrimary(ctx))
+	if err := db.Where("key = ? AND expires_at <= ?", key, record.CreatedAt).Delete(&Record{}).Error; err != nil {
+		return nil, false, err
+	}
+
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return nil, false, result.Error
+	}
+
+	if result.RowsAffected == 1 {
+		return record, true, nil
+	}
+
+	var existing Record
+	err = db.Where("key = ?", key).Take(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, ErrKeyNotFound
+	}
+
+	return &existing, false, err
+}
+
+// Complete implements Store.
+func (store *PostgresStore) Complete(ctx context.Context, record *Record, response Response, ttl time.Duration) error {
+	result := store.db.WithContext(ctx).
+		Model(&Record{}).
+		Where("key = ? AND owner = ?", record.Key, record.Owner).
+		Updates(&Record{
+			Completed:  true,
+			StatusCode: response.StatusCode,
+			Headers:    response.Headers,
+			Body:       response.Body,
+			ExpiresAt:  time.Now().UTC().Add(ttl),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrKeyNotHeld
+	}
+
+	return nil
+}
+
+// Release implements Store.
+func (store *PostgresStore) Release(ctx context.Context, record *Record) error {
+	return store.db.WithContext(ctx).
+		Where("key = ? AND owner = ? AND NOT completed", record.Key, record.Owner).
+		Delete(&Record{}).Error
+}
@@ -0,0 +1,56 @@
+package idempotency
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// ownerBytes is the size of the random token identifying the request
+// holding a key.
+const ownerBytes = 16
+
+type (
+	// Record is the state stored under an idempotency key.
+	Record struct {
+		Key         string            `gorm:"primaryKey" json:"key"`
+		Fingerprint string            `gorm:"not null" json:"fingerprint"`
+		Completed   bool              `gorm:"not null;default:false" json:"completed"`
+		StatusCode  int               `json:"status_code,omitempty"`
+		Headers     map[string]string `gorm:"serializer:json" json:"headers,omitempty"`
+		Body        []byte            `json:"body,omitempty"`
+		CreatedAt   time.Time         `gorm:"not null" json:"created_at"`
+		ExpiresAt   time.Time         `gorm:"not null;index" json:"expires_at"`
+		// Owner identifies the request that reserved the key; only it may
+		// complete or release the reservation.
+		Owner string `gorm:"not null;default:''" json:"owner"`
+	}
+
+	// Response is the final response of a request, captured for replay.
+	Response struct {
+		StatusCode int
+		Headers    map[string]string
+		Body       []byte
+	}
+)
+
+// TableName implements gorm's tabler interface.
+func (Record) TableName() string { return "idempotency_keys" }
+
+// newRecord returns the in-flight record reserving key for lockTTL, owned by
+// a new random token.
+func newRecord(key, fingerprint string, lockTTL time.Duration) (*Record, error) {
+	owner := make([]byte, ownerBytes)
+	if _, err := rand.Read(owner); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	return &Record{
+		Key:         key,
+		Fingerprint: fingerprint,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(lockTTL),
+		Owner:       hex.EncodeToString(owner),
+	}, nil
+}
@@ -0,0 +1,125 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bytedance/sonic"
+	grds "github.com/redis/go-redis/v9"
+)
+
+const redisKeyPrefix = "wasabi:idempotency:"
+
+var (
+	// completeScript replaces the record under KEYS[1] with ARGV[2] for
+	// ARGV[3] milliseconds, provided it is still owned by ARGV[1].
+	completeScript = grds.NewScript(`
+local raw = redis.call('GET', KEYS[1])
+if not raw or cjson.decode(raw).owner ~= ARGV[1] then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+return 1
+`)
+
+	// releaseScript deletes the in-flight record under KEYS[1], provided it
+	// is still owned by ARGV[1].
+	releaseScript = grds.NewScript(`
+local raw = redis.call('GET', KEYS[1])
+if not raw then
+	return 0
+end
+local record = cjson.decode(raw)
+if record.owner ~= ARGV[1] or record.completed then
+	return 0
+end
+return redis.call('DEL', KEYS[1])
+`)
+)
+
+// RedisStore keeps idempotency records in Redis.
+type RedisStore struct {
+	client *grds.Client
+}
+
+// NewRedisStore creates a new RedisStore.
+func NewRedisStore(client *grds.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// Begin implements Store.
+func (store *RedisStore) Begin(
+	ctx context.Context,
+	key, fingerprint string,
+	lockTTL time.Duration,
+) (*Record, bool, error) {
+	record, err := newRecord(key, fingerprint, lockTTL)
+	if err != nil {
+		return nil, false, err
+	}
+
+	raw, err := sonic.Marshal(record)
+	if err != nil {
+		return nil, false, err
+	}
+
+	acquired, err := store.client.SetNX(ctx, redisKeyPrefix+key, raw, lockTTL).Result()
+	if err != nil || acquired {
+		return record, acquired, err
+	}
+
+	existing, err := store.get(ctx, key)
+	if errors.Is(err, ErrKeyNotFound) {
+		// The previous holder expired between SETNX and GET, try once more.
+		acquired, err = store.client.SetNX(ctx, redisKeyPrefix+key, raw, lockTTL).Result()
+		return record, acquired, err
+	}
+
+	return existing, false, err
+}
+
+// Complete implements Store.
+func (store *RedisStore) Complete(ctx context.Context, record *Record, response Response, ttl time.Duration) error {
+	completed := *record
+	completed.Completed = true
+	completed.StatusCode = response.StatusCode
+	completed.Headers = response.Headers
+	completed.Body = response.Body
+	completed.ExpiresAt = time.Now().UTC().Add(ttl)
+
+	raw, err := sonic.Marshal(&completed)
+	if err != nil {
+		return err
+	}
+
+	stored, err := completeScript.Run(ctx, store.client,
+		[]string{redisKeyPrefix + record.Key}, record.Owner, raw, ttl.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+
+	if stored == 0 {
+		return ErrKeyNotHeld
+	}
+
+	return nil
+}
+
+// Release implements Store.
+func (store *RedisStore) Release(ctx context.Context, record *Record) error {
+	return releaseScript.Run(ctx, store.client, []string{redisKeyPrefix + record.Key}, record.Owner).Err()
+}
+
+func (store *RedisStore) get(ctx context.Context, key string) (*Record, error) {
+	raw, err := store.client.Get(ctx, redisKeyPrefix+key).Bytes()
+	if errors.Is(err, grds.Nil) {
+		return nil, ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var record Record
+	return &record, sonic.Unmarshal(raw, &record)
+}
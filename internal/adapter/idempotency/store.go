@@ -0,0 +1,47 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	grds "github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+type (
+	// Store persists idempotency records.
+	Store interface {
+		// Begin atomically reserves key for an in-flight request. When the key
+		// already exists, the existing record is returned and acquired is false.
+		Begin(ctx context.Context, key, fingerprint string, lockTTL time.Duration) (record *Record, acquired bool, err error)
+		// Complete stores the final response under the key reserved by record.
+		// It fails with ErrKeyNotHeld once the reservation has expired or was
+		// taken over by another request.
+		Complete(ctx context.Context, record *Record, response Response, ttl time.Duration) error
+		// Release drops the reservation of record so the request can be
+		// retried. A key held by another request is left alone.
+		Release(ctx context.Context, record *Record) error
+	}
+
+	// StoreParams holds the backends a Store can be built from.
+	StoreParams struct {
+		fx.In
+
+		Redis *grds.Client `optional:"true"`
+		DB    *gorm.DB     `optional:"true"`
+	}
+)
+
+// NewStore returns a Redis backed store when Redis is enabled,
+// and falls back to Postgres otherwise.
+func NewStore(params StoreParams) (Store, error) {
+	switch {
+	case params.Redis != nil:
+		return NewRedisStore(params.Redis), nil
+	case params.DB != nil:
+		return NewPostgresStore(params.DB), nil
+	default:
+		return nil, ErrNoStore
+	}
+}
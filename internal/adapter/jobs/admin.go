@@ -0,0 +1,74 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/widnyana/wasabi/internal/adapter/admin"
+)
+
+// NewAdminRoute serves the job endpoints of the admin server:
+//
+//	GET  /jobs/?state=&kind=&before_id=&limit=  lists jobs, newest first
+//	GET  /jobs/stats                            counts jobs by kind and state
+//	GET  /jobs/{id}                             returns a job
+//	POST /jobs/{id}/retry                       runs a dead or waiting job again
+func NewAdminRoute(queue *Queue) admin.Route {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /jobs/{$}", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		beforeID, _ := strconv.ParseInt(query.Get("before_id"), 10, 64)
+		limit, _ := strconv.Atoi(query.Get("limit"))
+
+		jobs, err := queue.List(r.Context(), Filter{
+			State:    query.Get("state"),
+			Kind:     query.Get("kind"),
+			BeforeID: beforeID,
+			Limit:    limit,
+		})
+		respond(w, jobs, err)
+	})
+	mux.HandleFunc("GET /jobs/stats", func(w http.ResponseWriter, r *http.Request) {
+		stats, err := queue.Stats(r.Context())
+		respond(w, stats, err)
+	})
+	mux.HandleFunc("GET /jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid job id", http.StatusBadRequest)
+			return
+		}
+
+		job, err := queue.Get(r.Context(), id)
+		respond(w, job, err)
+	})
+	mux.HandleFunc("POST /jobs/{id}/retry", func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid job id", http.StatusBadRequest)
+			return
+		}
+
+		job, err := queue.Retry(r.Context(), id)
+		respond(w, job, err)
+	})
+
+	return admin.Route{Pattern: "/jobs/", Handler: mux}
+}
+
+func respond(w http.ResponseWriter, value any, err error) {
+	switch {
+	case errors.Is(err, ErrJobNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrNotRetryable), errors.Is(err, ErrDuplicateJob):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(value)
+	}
+}
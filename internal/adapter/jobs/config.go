@@ -0,0 +1,27 @@
+package jobs
+
+import "time"
+
+// Config represents the configuration of the job queue.
+type Config struct {
+	// Enable runs the workers. Jobs can be enqueued either way.
+	Enable       bool          `envconfig:"enable"`
+	Concurrency  int           `envconfig:"concurrency" default:"10"`
+	PollInterval time.Duration `envconfig:"poll_interval" default:"1s"`
+	// VisibilityTimeout is how long a claimed job stays hidden from the other
+	// workers. Running jobs extend it; jobs of a crashed worker are retried
+	// once it expires.
+	VisibilityTimeout time.Duration `envconfig:"visibility_timeout" default:"5m"`
+	// MaxAttempts is the default number of attempts before a job is dead.
+	MaxAttempts     int           `envconfig:"max_attempts" default:"25"`
+	RetryBackoff    time.Duration `envconfig:"retry_backoff" default:"1s"`
+	MaxRetryBackoff time.Duration `envconfig:"max_retry_backoff" default:"1h"`
+	// DrainTimeout is how long running jobs may finish on shutdown before
+	// their context is canceled.
+	DrainTimeout time.Duration `envconfig:"drain_timeout" default:"30s"`
+	// MaintenanceInterval is how often the leader rescues expired jobs,
+	// drops old completed jobs and refreshes the queue metrics.
+	MaintenanceInterval time.Duration `envconfig:"maintenance_interval" default:"30s"`
+	// Retention is how long completed jobs are kept. Zero keeps them.
+	Retention time.Duration `envconfig:"retention" default:"168h"`
+}
@@ -0,0 +1,12 @@
+package jobs
+
+import "errors"
+
+var (
+	ErrDuplicateJob    = errors.New("a job with the same unique key is already queued")
+	ErrJobNotFound     = errors.New("job not found")
+	ErrNotRetryable    = errors.New("only dead or available jobs can be retried")
+	ErrNoKind          = errors.New("job handler has no kind")
+	ErrKindTaken       = errors.New("job kind already has a handler")
+	ErrInvalidInterval = errors.New("job worker intervals must be positive")
+)
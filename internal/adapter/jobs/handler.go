@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go.uber.org/fx"
+)
+
+type (
+	// Handler runs the jobs of Kind. A returned error retries the job with
+	// backoff until its attempts are exhausted.
+	Handler struct {
+		Kind   string
+		Handle func(ctx context.Context, job *Job) error
+		// Timeout bounds a single attempt. Zero leaves it unbounded.
+		Timeout time.Duration
+	}
+
+	// HandlerParams holds the handlers collected from the "job_handlers" value group.
+	HandlerParams struct {
+		fx.In
+
+		Handlers []Handler `group:"job_handlers"`
+	}
+)
+
+// AsHandler annotates a constructor returning a Handler so it joins the
+// "job_handlers" value group.
+func AsHandler(constructor any) any {
+	return fx.Annotate(constructor, fx.ResultTags(`group:"job_handlers"`))
+}
+
+// HandleJSON returns a Handler decoding the JSON payloads of kind into T.
+func HandleJSON[T any](kind string, handle func(ctx context.Context, payload T) error) Handler {
+	return Handler{
+		Kind: kind,
+		Handle: func(ctx context.Context, job *Job) error {
+			var payload T
+			if err := json.Unmarshal(job.Payload, &payload); err != nil {
+				return err
+			}
+			return handle(ctx, payload)
+		},
+	}
+}
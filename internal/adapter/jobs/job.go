@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	StateAvailable = "available"
+	StateRunning   = "running"
+	StateCompleted = "completed"
+	StateDead      = "dead"
+)
+
+type (
+	// Job is a unit of background work stored in Postgres.
+	Job struct {
+		ID          int64           `gorm:"primaryKey" json:"id"`
+		Kind        string          `gorm:"not null" json:"kind"`
+		Payload     json.RawMessage `gorm:"type:jsonb;not null" json:"payload"`
+		Priority    int             `gorm:"not null;default:0" json:"priority"`
+		State       string          `gorm:"not null" json:"state"`
+		Attempts    int             `gorm:"not null;default:0" json:"attempts"`
+		MaxAttempts int             `gorm:"not null" json:"max_attempts"`
+		UniqueKey   *string         `json:"unique_key,omitempty"`
+		RunAt       time.Time       `gorm:"not null" json:"run_at"`
+		LockedUntil *time.Time      `json:"locked_until,omitempty"`
+		LockedBy    string          `json:"locked_by,omitempty"`
+		LastError   string          `json:"last_error,omitempty"`
+		CreatedAt   time.Time       `gorm:"not null" json:"created_at"`
+		UpdatedAt   time.Time       `gorm:"not null" json:"updated_at"`
+		CompletedAt *time.Time      `json:"completed_at,omitempty"`
+	}
+
+	// Option customizes an enqueued job.
+	Option func(*Job)
+)
+
+// TableName implements gorm's tabler interface.
+func (Job) TableName() string { return "jobs" }
+
+// Priority sets the priority of the job. Higher priorities run first.
+func Priority(priority int) Option {
+	return func(job *Job) { job.Priority = priority }
+}
+
+// RunAt schedules the job to run no earlier than at.
+func RunAt(at time.Time) Option {
+	return func(job *Job) { job.RunAt = at.UTC() }
+}
+
+// Delay schedules the job to run no earlier than after d.
+func Delay(d time.Duration) Option {
+	return func(job *Job) { job.RunAt = time.Now().UTC().Add(d) }
+}
+
+// MaxAttempts overrides Config.MaxAttempts for the job.
+func MaxAttempts(n int) Option {
+	return func(job *Job) { job.MaxAttempts = n }
+}
+
+// Unique rejects the job with ErrDuplicateJob while another job with the same
+// key is available or running.
+func Unique(key string) Option {
+	return func(job *Job) { job.UniqueKey = &key }
+}
@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	lblKind   = "kind"
+	lblResult = "result"
+	lblState  = "state"
+
+	resultCompleted = "completed"
+	resultRetried   = "retried"
+	resultDead      = "dead"
+)
+
+// Metrics records the activity of the job queue.
+type Metrics struct {
+	enqueued  *prometheus.CounterVec
+	processed *prometheus.CounterVec
+	duration  *prometheus.HistogramVec
+	running   prometheus.Gauge
+	rescued   prometheus.Counter
+	depth     *prometheus.GaugeVec
+}
+
+// NewMetrics creates a new Metrics registering its metrics into registerer.
+func NewMetrics(registerer prometheus.Registerer) *Metrics {
+	factory := promauto.With(registerer)
+
+	return &Metrics{
+		enqueued: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "jobs_enqueued_total",
+			Help: "Number of jobs enqueued",
+		}, []string{lblKind}),
+		processed: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "jobs_processed_total",
+			Help: "Number of job attempts by outcome: completed, retried or dead",
+		}, []string{lblKind, lblResult}),
+		duration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "jobs_duration_seconds",
+			Help:    "Duration of job attempts in seconds",
+			Buckets: []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300, 900},
+		}, []string{lblKind}),
+		running: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "jobs_running",
+			Help: "Number of jobs running on this instance",
+		}),
+		rescued: factory.NewCounter(prometheus.CounterOpts{
+			Name: "jobs_rescued_total",
+			Help: "Number of running jobs released after their visibility timeout expired",
+		}),
+		depth: factory.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "jobs_queue_depth",
+			Help: "Number of jobs by state, completed jobs excluded",
+		}, []string{lblState}),
+	}
+}
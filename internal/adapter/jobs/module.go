@@ -0,0 +1,78 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/widnyana/wasabi/internal/adapter/admin"
+	"github.com/widnyana/wasabi/internal/adapter/database/pg/notify"
+	"go.uber.org/fx"
+)
+
+// wakeChannel is notified by a trigger whenever jobs are enqueued.
+const wakeChannel = "jobs"
+
+var (
+	Module = fx.Module("jobs", Providers, Invokers)
+
+	Providers = fx.Options(
+		fx.Provide(NewMetrics),
+		fx.Provide(NewQueue),
+		fx.Provide(NewWorkers),
+		fx.Provide(admin.AsRoute(NewAdminRoute)),
+		fx.Provide(notify.AsHandler(NewWakeHandler)),
+	)
+
+	Invokers = fx.Options(
+		fx.Invoke(HookWorkers),
+	)
+)
+
+// NewWakeHandler wakes the workers up as soon as jobs are committed, when the
+// notification listener runs. Without it the workers still poll.
+func NewWakeHandler(workers *Workers) notify.Handler {
+	return notify.Handler{
+		Channel: wakeChannel,
+		Handle: func(context.Context, string) error {
+			workers.Wake()
+			return nil
+		},
+	}
+}
+
+// HookWorkers runs the workers in the background when enabled and some
+// handler is registered. On stop, they stop claiming jobs and the running
+// jobs get DrainTimeout to finish before their context is canceled.
+func HookWorkers(lifecycle fx.Lifecycle, appCtx context.Context, cfg Config, workers *Workers) {
+	if !cfg.Enable || len(workers.Kinds()) == 0 {
+		return
+	}
+
+	fetchCtx, stopFetching := context.WithCancel(appCtx)
+	// Running jobs outlive the application context until the drain ends.
+	jobCtx, abandon := context.WithCancel(context.WithoutCancel(appCtx))
+	done := make(chan struct{})
+
+	lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				workers.Run(fetchCtx, jobCtx)
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			stopFetching()
+
+			select {
+			case <-done:
+			case <-ctx.Done():
+			case <-time.After(cfg.DrainTimeout):
+			}
+
+			abandon()
+			<-done
+			return nil
+		},
+	})
+}
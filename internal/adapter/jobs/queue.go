@@ -0,0 +1,164 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/widnyana/wasabi/internal/adapter/database/pg"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+type (
+	// Queue enqueues and inspects jobs.
+	Queue struct {
+		cfg     Config
+		tx      *pg.TxManager
+		metrics *Metrics
+	}
+
+	// Filter narrows the jobs returned by Queue.List.
+	Filter struct {
+		State string
+		Kind  string
+		// BeforeID pages through the jobs from the newest to the oldest.
+		BeforeID int64
+		Limit    int
+	}
+
+	// Stat counts the jobs of a kind in a state.
+	Stat struct {
+		Kind  string `json:"kind"`
+		State string `json:"state"`
+		Count int64  `json:"count"`
+	}
+)
+
+// NewQueue creates a new Queue.
+func NewQueue(cfg Config, tx *pg.TxManager, metrics *Metrics) *Queue {
+	return &Queue{cfg: cfg, tx: tx, metrics: metrics}
+}
+
+// Enqueue stores a job of kind with payload encoded as JSON. Inside a
+// transaction started by pg.TxManager.Do, the job only becomes visible to the
+// workers when the transaction commits.
+func (q *Queue) Enqueue(ctx context.Context, kind string, payload any, opts ...Option) (*Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	job := &Job{
+		Kind:        kind,
+		Payload:     data,
+		State:       StateAvailable,
+		MaxAttempts: q.cfg.MaxAttempts,
+		RunAt:       now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+
+	result := q.tx.DB(ctx).Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "unique_key"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "state IN ('available', 'running')"}}},
+		DoNothing:   true,
+	}).Create(job)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, ErrDuplicateJob
+	}
+
+	q.metrics.enqueued.WithLabelValues(kind).Inc()
+	return job, nil
+}
+
+// Get returns the job identified by id.
+func (q *Queue) Get(ctx context.Context, id int64) (*Job, error) {
+	var job Job
+	err := q.tx.DB(ctx).Where("id = ?", id).Take(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrJobNotFound
+	}
+
+	return &job, err
+}
+
+// List returns the jobs matching filter, newest first.
+func (q *Queue) List(ctx context.Context, filter Filter) ([]Job, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+
+	db := q.tx.DB(ctx).Order("id DESC").Limit(min(limit, maxListLimit))
+	if filter.State != "" {
+		db = db.Where("state = ?", filter.State)
+	}
+	if filter.Kind != "" {
+		db = db.Where("kind = ?", filter.Kind)
+	}
+	if filter.BeforeID > 0 {
+		db = db.Where("id < ?", filter.BeforeID)
+	}
+
+	var jobs []Job
+	return jobs, db.Find(&jobs).Error
+}
+
+// Retry makes a dead job, or an available job waiting for its next attempt,
+// run again now with a fresh set of attempts. A dead unique job can't be
+// retried while another job holds its unique key.
+func (q *Queue) Retry(ctx context.Context, id int64) (*Job, error) {
+	// The job is read back from the primary, which the update went to.
+	ctx = pg.WithPrimary(ctx)
+
+	result := q.tx.DB(ctx).Model(&Job{}).
+		Where("id = ? AND state IN ?", id, []string{StateDead, StateAvailable}).
+		Updates(map[string]any{
+			"state":      StateAvailable,
+			"attempts":   0,
+			"run_at":     time.Now().UTC(),
+			"updated_at": time.Now().UTC(),
+		})
+	if pg.IsUniqueViolation(result.Error) {
+		return nil, ErrDuplicateJob
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		if _, err := q.Get(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrNotRetryable
+	}
+
+	return q.Get(ctx, id)
+}
+
+// Stats counts the jobs by kind and state.
+func (q *Queue) Stats(ctx context.Context) ([]Stat, error) {
+	var stats []Stat
+	err := q.tx.DB(ctx).Model(&Job{}).
+		Select("kind, state, count(*) AS count").
+		Group("kind, state").
+		Order("kind, state").
+		Scan(&stats).Error
+
+	return stats, err
+}
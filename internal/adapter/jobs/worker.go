@@ -0,0 +1,411 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"github.com/widnyana/wasabi/internal/adapter/database/pg"
+	applog "github.com/widnyana/wasabi/internal/adapter/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// claimJobs marks the next available jobs as running for a worker, highest
+// priority first. SKIP LOCKED lets concurrent workers claim disjoint jobs.
+const claimJobs = `UPDATE jobs
+SET state = 'running', attempts = attempts + 1, locked_by = ?,
+    locked_until = now() + ? * interval '1 millisecond', updated_at = now()
+WHERE id IN (
+	SELECT id FROM jobs
+	WHERE state = 'available' AND run_at <= now() AND kind IN ?
+	ORDER BY priority DESC, run_at, id
+	LIMIT ?
+	FOR UPDATE SKIP LOCKED)
+RETURNING *`
+
+// rescueJobs releases the running jobs whose worker stopped extending their
+// visibility timeout, counting the lost run as an attempt.
+const rescueJobs = `UPDATE jobs
+SET state = CASE WHEN attempts >= max_attempts THEN 'dead' ELSE 'available' END,
+    run_at = now(), locked_until = NULL, locked_by = NULL,
+    last_error = 'visibility timeout expired', updated_at = now()
+WHERE state = 'running' AND locked_until < now()`
+
+// Workers claim and run the jobs of the registered handlers.
+type Workers struct {
+	cfg      Config
+	tx       *pg.TxManager
+	handlers map[string]Handler
+	kinds    []string
+	id       string
+	leader   *pg.Leader
+	metrics  *Metrics
+	tracer   trace.Tracer
+	logger   *otelzap.Logger
+	wake     chan struct{}
+}
+
+// NewWorkers creates Workers for the handlers of params. It fails on a
+// non-positive PollInterval or MaintenanceInterval, or a VisibilityTimeout
+// too short to be extended.
+func NewWorkers(
+	cfg Config,
+	tx *pg.TxManager,
+	sqlDB *sql.DB,
+	params HandlerParams,
+	metrics *Metrics,
+	logger *otelzap.Logger,
+) (*Workers, error) {
+	switch {
+	case cfg.PollInterval <= 0:
+		return nil, fmt.Errorf("%w: poll_interval %s", ErrInvalidInterval, cfg.PollInterval)
+	case cfg.MaintenanceInterval <= 0:
+		return nil, fmt.Errorf("%w: maintenance_interval %s", ErrInvalidInterval, cfg.MaintenanceInterval)
+	case cfg.VisibilityTimeout/3 <= 0:
+		// The heartbeat extends running jobs every third of it.
+		return nil, fmt.Errorf("%w: visibility_timeout %s", ErrInvalidInterval, cfg.VisibilityTimeout)
+	}
+
+	handlers := make(map[string]Handler, len(params.Handlers))
+	kinds := make([]string, 0, len(params.Handlers))
+	for _, handler := range params.Handlers {
+		if handler.Kind == "" {
+			return nil, ErrNoKind
+		}
+		if _, ok := handlers[handler.Kind]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrKindTaken, handler.Kind)
+		}
+		handlers[handler.Kind] = handler
+		kinds = append(kinds, handler.Kind)
+	}
+
+	leader, err := pg.NewLeader(sqlDB, "jobs", cfg.MaintenanceInterval, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Workers{
+		cfg:      cfg,
+		tx:       tx,
+		handlers: handlers,
+		kinds:    kinds,
+		id:       workerID(),
+		leader:   leader,
+		metrics:  metrics,
+		tracer:   otel.Tracer("jobs"),
+		logger:   logger,
+		wake:     make(chan struct{}, 1),
+	}, nil
+}
+
+// Kinds returns the kinds of jobs the workers run.
+func (w *Workers) Kinds() []string { return w.kinds }
+
+// Wake makes the workers poll now instead of at the next interval, e.g. when
+// notified of a new job.
+func (w *Workers) Wake() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run claims jobs until fetchCtx is done, then waits for the running jobs.
+// Jobs run with jobCtx, which is canceled to abandon a drain.
+func (w *Workers) Run(fetchCtx, jobCtx context.Context) {
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w.leader.Run(fetchCtx, w.maintain)
+	}()
+
+	slots := make(chan struct{}, w.cfg.Concurrency)
+	freed := make(chan struct{}, 1)
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		free := cap(slots) - len(slots)
+		claimed := 0
+		if free > 0 {
+			jobs, err := w.claim(fetchCtx, free)
+			if err != nil && fetchCtx.Err() == nil {
+				applog.Ctx(w.logger, fetchCtx).Error("failed to claim jobs", zap.Error(err))
+			}
+
+			claimed = len(jobs)
+			for _, job := range jobs {
+				slots <- struct{}{}
+				wg.Add(1)
+				go func() {
+					defer func() {
+						<-slots
+						select {
+						case freed <- struct{}{}:
+						default:
+						}
+						wg.Done()
+					}()
+					w.work(jobCtx, job)
+				}()
+			}
+		}
+
+		// Claiming every free slot means more jobs are probably waiting.
+		if claimed > 0 && claimed == free {
+			select {
+			case <-fetchCtx.Done():
+				wg.Wait()
+				return
+			case <-freed:
+			}
+			continue
+		}
+
+		select {
+		case <-fetchCtx.Done():
+			wg.Wait()
+			return
+		case <-ticker.C:
+		case <-w.wake:
+		case <-freed:
+		}
+	}
+}
+
+func (w *Workers) claim(ctx context.Context, limit int) ([]*Job, error) {
+	var jobs []*Job
+	err := w.tx.DB(ctx).
+		Raw(claimJobs, w.id, w.cfg.VisibilityTimeout.Milliseconds(), w.kinds, limit).
+		Scan(&jobs).Error
+
+	return jobs, err
+}
+
+func (w *Workers) work(ctx context.Context, job *Job) {
+	w.metrics.running.Inc()
+	defer w.metrics.running.Dec()
+
+	ctx = applog.WithFields(ctx, zap.Int64("job_id", job.ID), zap.String("job_kind", job.Kind))
+	ctx, span := w.tracer.Start(ctx, "job "+job.Kind,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.Int64("job.id", job.ID),
+			attribute.String("job.kind", job.Kind),
+			attribute.Int("job.attempt", job.Attempts),
+		),
+	)
+	defer span.End()
+
+	handler := w.handlers[job.Kind]
+	runCtx, cancel := ctx, context.CancelFunc(func() {})
+	if handler.Timeout > 0 {
+		runCtx, cancel = context.WithTimeout(ctx, handler.Timeout)
+	}
+
+	stop := w.heartbeat(runCtx, job)
+	begin := time.Now()
+	err := run(runCtx, handler, job)
+	elapsed := time.Since(begin)
+	stop()
+	cancel()
+
+	w.metrics.duration.WithLabelValues(job.Kind).Observe(elapsed.Seconds())
+
+	// The outcome is recorded even when the drain was abandoned.
+	ctx = context.WithoutCancel(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		err = w.fail(ctx, job, err)
+	} else {
+		err = w.complete(ctx, job)
+	}
+
+	if err != nil {
+		applog.Ctx(w.logger, ctx).Error("failed to record the job outcome", zap.Error(err))
+	}
+}
+
+// run calls the handler, turning a panic into an error.
+func run(ctx context.Context, handler Handler, job *Job) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job panicked: %v\n%s", recovered, debug.Stack())
+		}
+	}()
+
+	return handler.Handle(ctx, job)
+}
+
+// heartbeat extends the visibility timeout of job while it runs. The
+// returned function stops it.
+func (w *Workers) heartbeat(ctx context.Context, job *Job) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(w.cfg.VisibilityTimeout / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := w.tx.DB(ctx).Exec(`UPDATE jobs SET locked_until = now() + ? * interval '1 millisecond'
+					WHERE id = ? AND locked_by = ? AND state = 'running'`,
+					w.cfg.VisibilityTimeout.Milliseconds(), job.ID, w.id).Error
+				if err != nil && ctx.Err() == nil {
+					applog.Ctx(w.logger, ctx).Warn("failed to extend the job visibility timeout", zap.Error(err))
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func (w *Workers) complete(ctx context.Context, job *Job) error {
+	now := time.Now().UTC()
+	w.metrics.processed.WithLabelValues(job.Kind, resultCompleted).Inc()
+
+	return w.release(ctx, job, map[string]any{
+		"state":        StateCompleted,
+		"completed_at": now,
+		"last_error":   "",
+	})
+}
+
+// fail retries job with backoff, or marks it dead once its attempts are exhausted.
+func (w *Workers) fail(ctx context.Context, job *Job, cause error) error {
+	updates := map[string]any{"last_error": cause.Error()}
+	fields := []zap.Field{zap.Int("attempts", job.Attempts), zap.Error(cause)}
+
+	if job.Attempts >= job.MaxAttempts {
+		updates["state"] = StateDead
+		w.metrics.processed.WithLabelValues(job.Kind, resultDead).Inc()
+		applog.Ctx(w.logger, ctx).Error("job dead after max attempts", fields...)
+	} else {
+		backoff := w.backoff(job.Attempts)
+		updates["state"] = StateAvailable
+		updates["run_at"] = time.Now().UTC().Add(backoff)
+		w.metrics.processed.WithLabelValues(job.Kind, resultRetried).Inc()
+		applog.Ctx(w.logger, ctx).Warn("job failed", append(fields, zap.Duration("backoff", backoff))...)
+	}
+
+	return w.release(ctx, job, updates)
+}
+
+// release applies updates to job unless its lease was lost to another worker.
+func (w *Workers) release(ctx context.Context, job *Job, updates map[string]any) error {
+	updates["locked_until"] = nil
+	updates["locked_by"] = nil
+	updates["updated_at"] = time.Now().UTC()
+
+	result := w.tx.DB(ctx).Model(&Job{}).
+		Where("id = ? AND locked_by = ? AND state = ?", job.ID, w.id, StateRunning).
+		Updates(updates)
+	if result.Error == nil && result.RowsAffected == 0 {
+		applog.Ctx(w.logger, ctx).Warn("job lease lost before its outcome was recorded")
+	}
+
+	return result.Error
+}
+
+func (w *Workers) backoff(attempts int) time.Duration {
+	backoff := w.cfg.RetryBackoff
+	for i := 1; i < attempts && backoff < w.cfg.MaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, w.cfg.MaxRetryBackoff)
+}
+
+// maintain runs on the leader: it rescues the jobs whose visibility timeout
+// expired, drops the completed jobs older than Retention and refreshes the
+// queue depth.
+func (w *Workers) maintain(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.MaintenanceInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.maintainOnce(ctx); err != nil && ctx.Err() == nil {
+			applog.Ctx(w.logger, ctx).Warn("failed to maintain the job queue", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Workers) maintainOnce(ctx context.Context) error {
+	db := w.tx.DB(ctx)
+
+	result := db.Exec(rescueJobs)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		w.metrics.rescued.Add(float64(result.RowsAffected))
+		applog.Ctx(w.logger, ctx).Warn("rescued jobs after their visibility timeout", zap.Int64("jobs", result.RowsAffected))
+	}
+
+	if w.cfg.Retention > 0 {
+		err := db.Where("state = ? AND completed_at < ?", StateCompleted, time.Now().UTC().Add(-w.cfg.Retention)).
+			Delete(&Job{}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	var depths []struct {
+		State string
+		Count int64
+	}
+	err := db.Model(&Job{}).
+		Select("state, count(*) AS count").
+		Where("state <> ?", StateCompleted).
+		Group("state").
+		Scan(&depths).Error
+	if err != nil {
+		return err
+	}
+
+	w.metrics.depth.Reset()
+	for _, depth := range depths {
+		w.metrics.depth.WithLabelValues(depth.State).Set(float64(depth.Count))
+	}
+
+	return nil
+}
+
+// workerID identifies this process in locked_by.
+func workerID() string {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
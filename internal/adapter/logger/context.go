@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+)
+
+type fieldsKey struct{}
+
+// WithFields returns a copy of ctx carrying fields in addition to the ones
+// already attached to it. Ctx adds them to every entry logged with ctx.
+func WithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+
+	parent, _ := ctx.Value(fieldsKey{}).([]zap.Field)
+	merged := make([]zap.Field, 0, len(parent)+len(fields))
+	merged = append(merged, parent...)
+	merged = append(merged, fields...)
+
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// FieldsFromContext returns the fields attached to ctx with WithFields,
+// followed by the trace correlation fields of its span.
+func FieldsFromContext(ctx context.Context) []zap.Field {
+	fields, _ := ctx.Value(fieldsKey{}).([]zap.Field)
+	trace := TraceFields(ctx)
+	if len(trace) == 0 {
+		return fields
+	}
+
+	return append(fields[:len(fields):len(fields)], trace...)
+}
+
+// Ctx returns lgr bound to ctx, with the fields of FieldsFromContext added
+// to every entry and to its otel log record. Use it in place of lgr.Ctx(ctx).
+// The fields are not added by the sugared logger of the result.
+func Ctx(lgr *otelzap.Logger, ctx context.Context) otelzap.LoggerWithCtx {
+	fields := FieldsFromContext(ctx)
+	if len(fields) == 0 {
+		return lgr.Ctx(ctx)
+	}
+
+	return lgr.Clone(otelzap.WithExtraFields(fields...)).Ctx(ctx)
+}
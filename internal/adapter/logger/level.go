@@ -0,0 +1,325 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Levels holds the global AtomicLevel together with per-logger-name overrides,
+// e.g. debug for "gorm" only. Changes may revert automatically after a TTL.
+type Levels struct {
+	global zap.AtomicLevel
+	// otelOptions are the options of the root logger, reused by Named.
+	otelOptions []otelzap.Option
+
+	mu        sync.RWMutex
+	base      zapcore.Level
+	baseOver  map[string]zapcore.Level
+	overrides map[string]zapcore.Level
+	timers    map[string]*time.Timer
+}
+
+type levelRequest struct {
+	Level  string `json:"level"`
+	Logger string `json:"logger"`
+	TTL    string `json:"ttl"`
+}
+
+type levelState struct {
+	Level     string            `json:"level"`
+	Base      string            `json:"base"`
+	Overrides map[string]string `json:"overrides"`
+}
+
+// NewLevels creates Levels from config, driving the given AtomicLevel.
+func NewLevels(cfg Config, global zap.AtomicLevel) (*Levels, error) {
+	levels := &Levels{
+		global: global,
+		otelOptions: []otelzap.Option{
+			otelzap.WithErrorStatusLevel(zapcore.WarnLevel),
+			otelzap.WithMinLevel(global.Level()),
+			otelzap.WithCaller(true),
+			otelzap.WithCallerDepth(callerDepthAdjustment),
+		},
+		base:   global.Level(),
+		timers: map[string]*time.Timer{},
+	}
+
+	overrides, err := parseOverrides(cfg.Overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	levels.baseOver = overrides
+	levels.overrides = cloneLevels(overrides)
+
+	return levels, nil
+}
+
+// Enabled reports whether an entry of the named logger at lvl should be written.
+// The most specific dotted prefix of name with an override wins.
+func (levels *Levels) Enabled(name string, lvl zapcore.Level) bool {
+	levels.mu.RLock()
+	defer levels.mu.RUnlock()
+
+	for candidate := name; candidate != ""; candidate = parentName(candidate) {
+		if override, ok := levels.overrides[candidate]; ok {
+			return lvl >= override
+		}
+	}
+
+	return levels.global.Enabled(lvl)
+}
+
+// minLevel is the lowest level any logger may write at.
+func (levels *Levels) minLevel() zapcore.Level {
+	levels.mu.RLock()
+	defer levels.mu.RUnlock()
+
+	lowest := levels.global.Level()
+	for _, lvl := range levels.overrides {
+		lowest = min(lowest, lvl)
+	}
+
+	return lowest
+}
+
+// SetLevel changes the global level. A positive ttl reverts it to the
+// configured level afterwards.
+func (levels *Levels) SetLevel(lvl zapcore.Level, ttl time.Duration) {
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+
+	levels.global.SetLevel(lvl)
+	levels.scheduleRevert("", ttl)
+}
+
+// SetOverride changes the level of a named logger. A positive ttl reverts
+// it to its configured override, or removes it, afterwards.
+func (levels *Levels) SetOverride(name string, lvl zapcore.Level, ttl time.Duration) {
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+
+	levels.overrides[name] = lvl
+	levels.scheduleRevert(name, ttl)
+}
+
+// ClearOverride removes the override of a named logger.
+func (levels *Levels) ClearOverride(name string) {
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+
+	delete(levels.overrides, name)
+	levels.stopTimer(name)
+}
+
+// Toggle switches the global level between the configured level and debug.
+func (levels *Levels) Toggle() zapcore.Level {
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+
+	next := zapcore.DebugLevel
+	if levels.global.Level() == zapcore.DebugLevel {
+		next = levels.base
+	}
+
+	levels.global.SetLevel(next)
+	levels.stopTimer("")
+
+	return next
+}
+
+// Apply resets the levels to the ones in cfg, e.g. after a config reload.
+func (levels *Levels) Apply(cfg Config) error {
+	lvl, err := levelFromString(cfg.Level)
+	if err != nil {
+		return err
+	}
+
+	overrides, err := parseOverrides(cfg.Overrides)
+	if err != nil {
+		return err
+	}
+
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+
+	for name := range levels.timers {
+		levels.stopTimer(name)
+	}
+
+	levels.base = lvl
+	levels.baseOver = overrides
+	levels.overrides = cloneLevels(overrides)
+	levels.global.SetLevel(lvl)
+
+	return nil
+}
+
+// ServeHTTP exposes the levels over HTTP.
+//
+//	GET    returns the current levels
+//	PUT    {"level":"debug","logger":"gorm","ttl":"10m"} sets a level; logger and ttl are optional
+//	DELETE ?logger=gorm clears an override
+func (levels *Levels) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req levelRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		lvl, err := levelFromString(req.Level)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var ttl time.Duration
+		if req.TTL != "" {
+			if ttl, err = time.ParseDuration(req.TTL); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		if req.Logger == "" {
+			levels.SetLevel(lvl, ttl)
+		} else {
+			levels.SetOverride(req.Logger, lvl, ttl)
+		}
+	case http.MethodDelete:
+		levels.ClearOverride(r.URL.Query().Get("logger"))
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(levels.state())
+}
+
+func (levels *Levels) state() levelState {
+	levels.mu.RLock()
+	defer levels.mu.RUnlock()
+
+	state := levelState{
+		Level:     levels.global.Level().String(),
+		Base:      levels.base.String(),
+		Overrides: make(map[string]string, len(levels.overrides)),
+	}
+	for name, lvl := range levels.overrides {
+		state.Overrides[name] = lvl.String()
+	}
+
+	return state
+}
+
+// scheduleRevert must be called with mu held. An empty name is the global level.
+func (levels *Levels) scheduleRevert(name string, ttl time.Duration) {
+	levels.stopTimer(name)
+	if ttl <= 0 {
+		return
+	}
+
+	levels.timers[name] = time.AfterFunc(ttl, func() {
+		levels.mu.Lock()
+		defer levels.mu.Unlock()
+
+		delete(levels.timers, name)
+		if name == "" {
+			levels.global.SetLevel(levels.base)
+			return
+		}
+
+		if lvl, ok := levels.baseOver[name]; ok {
+			levels.overrides[name] = lvl
+		} else {
+			delete(levels.overrides, name)
+		}
+	})
+}
+
+// stopTimer must be called with mu held.
+func (levels *Levels) stopTimer(name string) {
+	if timer, ok := levels.timers[name]; ok {
+		timer.Stop()
+		delete(levels.timers, name)
+	}
+}
+
+// levelCore filters entries through Levels by logger name. The wrapped core
+// must accept every level, filtering is done here.
+type levelCore struct {
+	zapcore.Core
+	levels *Levels
+}
+
+func newLevelCore(core zapcore.Core, levels *Levels) zapcore.Core {
+	return &levelCore{Core: core, levels: levels}
+}
+
+// Enabled implements zapcore.LevelEnabler.
+func (core *levelCore) Enabled(lvl zapcore.Level) bool {
+	return lvl >= core.levels.minLevel()
+}
+
+// Level implements zapcore.LevelOf.
+func (core *levelCore) Level() zapcore.Level {
+	return core.levels.minLevel()
+}
+
+// With implements zapcore.Core.
+func (core *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: core.Core.With(fields), levels: core.levels}
+}
+
+// Check implements zapcore.Core.
+func (core *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if core.levels.Enabled(ent.LoggerName, ent.Level) {
+		return core.Core.Check(ent, ce)
+	}
+
+	return ce
+}
+
+func parseOverrides(raw map[string]string) (map[string]zapcore.Level, error) {
+	overrides := make(map[string]zapcore.Level, len(raw))
+	for name, value := range raw {
+		lvl, err := levelFromString(value)
+		if err != nil {
+			return nil, err
+		}
+		overrides[name] = lvl
+	}
+
+	return overrides, nil
+}
+
+func cloneLevels(levels map[string]zapcore.Level) map[string]zapcore.Level {
+	clone := make(map[string]zapcore.Level, len(levels))
+	for name, lvl := range levels {
+		clone[name] = lvl
+	}
+
+	return clone
+}
+
+func parentName(name string) string {
+	for i := len(name) - 1; i >= 0; i-- {
+		if name[i] == '.' {
+			return name[:i]
+		}
+	}
+
+	return ""
+}
@@ -1,9 +1,10 @@
 package logger
 
 import (
-	"os"
+	"context"
 
 	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"github.com/widnyana/wasabi/internal/adapter/redact"
 	"go.uber.org/fx"
 	"go.uber.org/fx/fxevent"
 	"go.uber.org/zap"
@@ -13,13 +14,41 @@ import (
 type Config struct {
 	Level       string `mapstructure:"level" default:"INFO"`
 	CallerDepth int    `mapstructure:"caller_depth" default:"0"`
+	// Overrides sets levels per logger name, e.g. gorm:debug,fiber:warn.
+	Overrides map[string]string `envconfig:"overrides"`
+	// LevelsFile is a JSON file re-read on SIGHUP, e.g.
+	// {"level":"info","overrides":{"gorm":"debug"}}. Reloading is
+	// disabled when empty.
+	LevelsFile string `envconfig:"levels_file"`
+	// Outputs describes the log sinks, see Outputs for the format.
+	Outputs Outputs `envconfig:"outputs" default:"dest=stdout,encoding=json"`
+	// FxEventOutputs routes fx lifecycle events to separate sinks.
+	// When empty they go through the application logger.
+	FxEventOutputs Outputs `envconfig:"fx_event_outputs"`
+	// Sampling protects the sinks against log storms.
+	Sampling SamplingConfig `envconfig:"sampling"`
 }
 
 var Module = fx.Options(
+	// NewAtomicLevel provides the zap.AtomicLevel shared by the logger core,
+	// so the level can be changed at runtime.
+	fx.Provide(NewAtomicLevel),
+
+	// NewLevels provides the per-logger-name overrides on top of the
+	// AtomicLevel. HookSignals wires SIGUSR1 and SIGHUP (LevelsFile) to it.
+	fx.Provide(NewLevels),
+	fx.Invoke(HookSignals),
+
+	// NewSampler provides the sampler of the logger core, nil when
+	// sampling is disabled. Its dropped entries are exported as a metric.
+	fx.Provide(NewSampler),
+	fx.Invoke(RegisterSamplingMetrics),
+
 	// GetLogger provides an *otelzap.Logger instance. This logger is
 	// configured with JSON encoding, writing to standard output, and
 	// using the Info level for logging. It also integrates with
-	// OpenTelemetry for tracing context.
+	// OpenTelemetry for tracing context. Messages and fields go through
+	// the *redact.Policy before they reach the outputs.
 	fx.Provide(GetLogger),
 
 	// This anonymous function provides an *otelzap.SugaredLogger.
@@ -39,20 +68,33 @@ var Module = fx.Options(
 	}),
 
 	// WithLogger configures Fx's internal event logging to use the
-	// provided *otelzap.Logger. It creates a fxevent.ZapLogger,
-	// sets its underlying Zap Logger, and then sets the log level for
-	// Fx's events to DebugLevel, ensuring verbose output of Fx lifecycle events.
-	fx.WithLogger(func(logger *otelzap.Logger) fxevent.Logger {
-		l := &fxevent.ZapLogger{Logger: logger.Logger}
+	// provided *otelzap.Logger, or the sinks of FxEventOutputs when set.
+	// It creates a fxevent.ZapLogger, sets its underlying Zap Logger, and
+	// then sets the log level for Fx's events to DebugLevel, ensuring
+	// verbose output of Fx lifecycle events.
+	fx.WithLogger(func(cfg Config, logger *otelzap.Logger, redactor *redact.Policy) (fxevent.Logger, error) {
+		zl := logger.Logger
+		if len(cfg.FxEventOutputs) > 0 {
+			core, err := cfg.FxEventOutputs.newTee(encoderConfig())
+			if err != nil {
+				return nil, err
+			}
+			zl = zap.New(redact.Core(core, redactor)).Named("fx")
+		}
+
+		l := &fxevent.ZapLogger{Logger: zl}
 		l.UseLogLevel(zap.DebugLevel)
-		return l
+		return l, nil
 	}),
+
+	// HookSync flushes buffered log entries when the application stops.
+	fx.Invoke(HookSync),
 )
 
 const callerDepthAdjustment = 0
 
-func newLogger(cfg Config, level zapcore.Level) *zap.Logger {
-	encoderCfg := zapcore.EncoderConfig{
+func encoderConfig() zapcore.EncoderConfig {
+	return zapcore.EncoderConfig{
 		TimeKey:        "ts",
 		MessageKey:     "msg",
 		LevelKey:       "level",
@@ -65,41 +107,65 @@ func newLogger(cfg Config, level zapcore.Level) *zap.Logger {
 		EncodeDuration: zapcore.StringDurationEncoder,
 		EncodeCaller:   zapcore.ShortCallerEncoder,
 	}
+}
+
+func newLogger(cfg Config, levels *Levels, sampler *Sampler, redactor *redact.Policy) (*zap.Logger, error) {
+	tee, err := cfg.Outputs.newTee(encoderConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	// Levels does the filtering, the outputs only apply their own thresholds.
+	// Redaction runs last so that dropped entries are never scrubbed.
+	core := newLevelCore(sampler.wrap(redact.Core(tee, redactor)), levels)
 
-	core := zapcore.NewCore(
-		zapcore.NewJSONEncoder(encoderCfg),
-		os.Stdout,
-		level,
-	)
-
-	return zap.New(core, zap.AddCaller()).
-		WithOptions(
-			zap.WithCaller(true),
-			zap.AddStacktrace(zap.ErrorLevel),
-			zap.AddCallerSkip(cfg.CallerDepth),
-		)
+	return zap.New(core,
+		zap.AddCaller(),
+		zap.AddStacktrace(zap.ErrorLevel),
+		zap.AddCallerSkip(cfg.CallerDepth),
+	), nil
 }
 
-func GetLogger(cfg Config) (*otelzap.Logger, error) {
+// NewAtomicLevel creates the runtime adjustable level, starting at the configured level.
+func NewAtomicLevel(cfg Config) zap.AtomicLevel {
 	level, err := levelFromString(cfg.Level)
 	if err != nil {
 		level = zapcore.InfoLevel
 	}
 
-	logger := otelzap.New(newLogger(cfg, level),
-		otelzap.WithErrorStatusLevel(zapcore.WarnLevel),
-		otelzap.WithMinLevel(level),
-		otelzap.WithCaller(true),
-		otelzap.WithCallerDepth(callerDepthAdjustment),
-		otelzap.WithMinLevel(level),
-		otelzap.WithErrorStatusLevel(zapcore.WarnLevel),
-	)
+	return zap.NewAtomicLevelAt(level)
+}
+
+func GetLogger(cfg Config, levels *Levels, sampler *Sampler, redactor *redact.Policy) (*otelzap.Logger, error) {
+	zl, err := newLogger(cfg, levels, sampler, redactor)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := otelzap.New(zl, levels.otelOptions...)
 
 	_ = otelzap.ReplaceGlobals(logger)
 
 	return logger, nil
 }
 
+// Named returns a child logger with the given name, so its level can be
+// overridden separately, e.g. "gorm".
+func (levels *Levels) Named(logger *otelzap.Logger, name string) *otelzap.Logger {
+	return otelzap.New(logger.Logger.Named(name), levels.otelOptions...)
+}
+
+// HookSync flushes the logger when the application stops.
+func HookSync(lifecycle fx.Lifecycle, logger *otelzap.Logger) {
+	lifecycle.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			// Syncing a terminal returns EINVAL on some platforms, ignore it.
+			_ = logger.Sync()
+			return nil
+		},
+	})
+}
+
 func levelFromString(s string) (zapcore.Level, error) {
 	var level zapcore.Level
 	err := level.UnmarshalText([]byte(s))
@@ -0,0 +1,169 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	DestStdout = "stdout"
+	DestStderr = "stderr"
+	DestFile   = "file"
+
+	EncodingJSON    = "json"
+	EncodingConsole = "console"
+)
+
+var (
+	ErrUnknownDest     = errors.New("unknown log output destination")
+	ErrUnknownEncoding = errors.New("unknown log output encoding")
+	ErrMissingPath     = errors.New("file log output requires a path")
+)
+
+type (
+	// Output describes a single log sink.
+	Output struct {
+		// Dest is stdout, stderr or file.
+		Dest string
+		// Encoding is json or console.
+		Encoding string
+		// Color enables colored levels for the console encoding.
+		Color bool
+		// Level is the minimum level written to this sink, on top of the logger level.
+		Level zapcore.Level
+
+		// Path and the rotation settings apply to file outputs.
+		Path       string
+		MaxSizeMB  int
+		MaxAgeDays int
+		MaxBackups int
+		Compress   bool
+	}
+
+	// Outputs is a list of sinks, decoded from a string of outputs separated by ';',
+	// each being a comma separated list of key=value pairs:
+	//
+	//	dest=stderr,level=error;dest=file,path=/var/log/wasabi.log,max_size_mb=100,compress=true
+	//
+	// Keys are dest, encoding, color, level, path, max_size_mb, max_age_days,
+	// max_backups and compress.
+	Outputs []Output
+)
+
+// Decode implements envconfig.Decoder.
+func (outputs *Outputs) Decode(value string) error {
+	*outputs = nil
+	for _, spec := range strings.Split(value, ";") {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+
+		output, err := parseOutput(spec)
+		if err != nil {
+			return err
+		}
+		*outputs = append(*outputs, output)
+	}
+
+	return nil
+}
+
+func parseOutput(spec string) (Output, error) {
+	output := Output{Dest: DestStdout, Encoding: EncodingJSON, Level: zapcore.DebugLevel}
+
+	for _, pair := range strings.Split(spec, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pair), "=")
+		var err error
+		switch key {
+		case "dest":
+			output.Dest = value
+		case "encoding":
+			output.Encoding = value
+		case "color":
+			output.Color, err = strconv.ParseBool(value)
+		case "level":
+			output.Level, err = levelFromString(value)
+		case "path":
+			output.Path = value
+		case "max_size_mb":
+			output.MaxSizeMB, err = strconv.Atoi(value)
+		case "max_age_days":
+			output.MaxAgeDays, err = strconv.Atoi(value)
+		case "max_backups":
+			output.MaxBackups, err = strconv.Atoi(value)
+		case "compress":
+			output.Compress, err = strconv.ParseBool(value)
+		default:
+			err = fmt.Errorf("unknown log output key %q", key)
+		}
+		if err != nil {
+			return output, fmt.Errorf("log output %q: %w", spec, err)
+		}
+	}
+
+	return output, nil
+}
+
+// newCore builds the core of a single output.
+func (output Output) newCore(encoderCfg zapcore.EncoderConfig) (zapcore.Core, error) {
+	var encoder zapcore.Encoder
+	switch output.Encoding {
+	case EncodingJSON, "":
+		encoder = zapcore.NewJSONEncoder(encoderCfg)
+	case EncodingConsole:
+		if output.Color {
+			encoderCfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		}
+		encoder = zapcore.NewConsoleEncoder(encoderCfg)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEncoding, output.Encoding)
+	}
+
+	var sink zapcore.WriteSyncer
+	switch output.Dest {
+	case DestStdout, "":
+		sink = zapcore.Lock(os.Stdout)
+	case DestStderr:
+		sink = zapcore.Lock(os.Stderr)
+	case DestFile:
+		if output.Path == "" {
+			return nil, ErrMissingPath
+		}
+		sink = zapcore.AddSync(&lumberjack.Logger{
+			Filename:   output.Path,
+			MaxSize:    output.MaxSizeMB,
+			MaxAge:     output.MaxAgeDays,
+			MaxBackups: output.MaxBackups,
+			Compress:   output.Compress,
+			LocalTime:  false,
+		})
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDest, output.Dest)
+	}
+
+	return zapcore.NewCore(encoder, sink, output.Level), nil
+}
+
+// newTee builds one core writing to every output.
+func (outputs Outputs) newTee(encoderCfg zapcore.EncoderConfig) (zapcore.Core, error) {
+	if len(outputs) == 0 {
+		outputs = Outputs{{Dest: DestStdout, Encoding: EncodingJSON, Level: zapcore.DebugLevel}}
+	}
+
+	cores := make([]zapcore.Core, 0, len(outputs))
+	for _, output := range outputs {
+		core, err := output.newCore(encoderCfg)
+		if err != nil {
+			return nil, err
+		}
+		cores = append(cores, core)
+	}
+
+	return zapcore.NewTee(cores...), nil
+}
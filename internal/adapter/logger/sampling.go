@@ -0,0 +1,253 @@
+package logger
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/fx"
+	"go.uber.org/zap/zapcore"
+)
+
+const samplerBuckets = 4096
+
+type (
+	// SamplingConfig configures log sampling. Within each Tick, the first
+	// Initial entries with the same level and message are logged, then every
+	// Thereafter-th. Errors of sampled-in traces are always logged.
+	SamplingConfig struct {
+		Enable     bool          `envconfig:"enable"`
+		Tick       time.Duration `envconfig:"tick" default:"1s"`
+		Initial    int           `envconfig:"initial" default:"100"`
+		Thereafter int           `envconfig:"thereafter" default:"100"`
+		// Levels overrides the policy per level as initial/thereafter,
+		// e.g. debug:10/1000,error:500/10. A thereafter of 0 drops the rest.
+		Levels map[string]string `envconfig:"levels"`
+	}
+
+	samplePolicy struct {
+		initial    uint64
+		thereafter uint64
+	}
+
+	sampleCounter struct {
+		resetAt atomic.Int64
+		count   atomic.Uint64
+	}
+
+	// Sampler keeps the sampling state and counts dropped entries per level.
+	Sampler struct {
+		tick     time.Duration
+		policies map[zapcore.Level]samplePolicy
+		fallback samplePolicy
+		counters [zapcore.FatalLevel - zapcore.DebugLevel + 1][samplerBuckets]sampleCounter
+		dropped  [zapcore.FatalLevel - zapcore.DebugLevel + 1]atomic.Uint64
+	}
+
+	samplingCore struct {
+		zapcore.Core
+		sampler *Sampler
+		// inTrace is set once fields of a sampled-in trace were added with With.
+		inTrace bool
+	}
+
+	// sampledEntry decides in Write whether an error reaches the sinks that
+	// accepted it, once the fields of the call site are known.
+	sampledEntry struct {
+		checked *zapcore.CheckedEntry
+		sampler *Sampler
+	}
+
+	// SamplingMetricsParams holds the optional registry for the dropped entries metric.
+	SamplingMetricsParams struct {
+		fx.In
+
+		Sampler    *Sampler
+		Registerer prometheus.Registerer `optional:"true"`
+	}
+)
+
+// NewSampler creates a Sampler from config. It returns nil when sampling is disabled.
+func NewSampler(cfg Config) (*Sampler, error) {
+	sc := cfg.Sampling
+	if !sc.Enable {
+		return nil, nil
+	}
+
+	sampler := &Sampler{
+		tick:     sc.Tick,
+		policies: map[zapcore.Level]samplePolicy{},
+		fallback: samplePolicy{initial: uint64(max(sc.Initial, 0)), thereafter: uint64(max(sc.Thereafter, 0))},
+	}
+
+	for name, spec := range sc.Levels {
+		lvl, err := levelFromString(name)
+		if err != nil {
+			return nil, err
+		}
+
+		initial, thereafter, ok := strings.Cut(spec, "/")
+		first, err1 := strconv.ParseUint(initial, 10, 64)
+		rest, err2 := strconv.ParseUint(thereafter, 10, 64)
+		if !ok || err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("invalid sampling policy %q for level %s, expected initial/thereafter", spec, name)
+		}
+
+		sampler.policies[lvl] = samplePolicy{initial: first, thereafter: rest}
+	}
+
+	return sampler, nil
+}
+
+// Dropped returns the number of entries dropped at lvl.
+func (sampler *Sampler) Dropped(lvl zapcore.Level) uint64 {
+	if lvl < zapcore.DebugLevel || lvl > zapcore.FatalLevel {
+		return 0
+	}
+
+	return sampler.dropped[lvl-zapcore.DebugLevel].Load()
+}
+
+// sample reports whether the entry is kept, counting it as dropped otherwise.
+func (sampler *Sampler) sample(ent zapcore.Entry) bool {
+	if ent.Level < zapcore.DebugLevel || ent.Level > zapcore.FatalLevel {
+		return true
+	}
+
+	policy, ok := sampler.policies[ent.Level]
+	if !ok {
+		policy = sampler.fallback
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(ent.LoggerName))
+	_, _ = hash.Write([]byte(ent.Message))
+	counter := &sampler.counters[ent.Level-zapcore.DebugLevel][hash.Sum32()%samplerBuckets]
+
+	now := ent.Time.UnixNano()
+	resetAt := counter.resetAt.Load()
+	var n uint64
+	if now > resetAt {
+		// First entry of a new tick resets the counter.
+		if counter.resetAt.CompareAndSwap(resetAt, now+sampler.tick.Nanoseconds()) {
+			counter.count.Store(1)
+			n = 1
+		} else {
+			n = counter.count.Add(1)
+		}
+	} else {
+		n = counter.count.Add(1)
+	}
+
+	if n <= policy.initial || (policy.thereafter > 0 && (n-policy.initial)%policy.thereafter == 0) {
+		return true
+	}
+
+	sampler.dropped[ent.Level-zapcore.DebugLevel].Add(1)
+	return false
+}
+
+// wrap returns core sampled by the sampler, or core itself when sampling is disabled.
+func (sampler *Sampler) wrap(core zapcore.Core) zapcore.Core {
+	if sampler == nil {
+		return core
+	}
+
+	return &samplingCore{Core: core, sampler: sampler}
+}
+
+// With implements zapcore.Core.
+func (core *samplingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &samplingCore{
+		Core:    core.Core.With(fields),
+		sampler: core.sampler,
+		inTrace: core.inTrace || inSampledTrace(fields),
+	}
+}
+
+// Check implements zapcore.Core. The wrapped core checks the entry itself so
+// every output keeps its own level. Errors are decided in Write, where the
+// fields tell whether they belong to a sampled-in trace.
+func (core *samplingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !core.Enabled(ent.Level) {
+		return ce
+	}
+
+	if ent.Level < zapcore.ErrorLevel {
+		if !core.sampler.sample(ent) {
+			return ce
+		}
+		return core.Core.Check(ent, ce)
+	}
+
+	if core.inTrace {
+		return core.Core.Check(ent, ce)
+	}
+
+	checked := core.Core.Check(ent, nil)
+	if checked == nil {
+		return ce
+	}
+
+	return ce.AddCore(ent, &sampledEntry{checked: checked, sampler: core.sampler})
+}
+
+func (*sampledEntry) Enabled(zapcore.Level) bool { return true }
+
+func (e *sampledEntry) With([]zapcore.Field) zapcore.Core { return e }
+
+func (*sampledEntry) Check(_ zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce
+}
+
+// Write is called once, with the entry completed by the logger after Check.
+func (e *sampledEntry) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if !inSampledTrace(fields) && !e.sampler.sample(ent) {
+		return nil
+	}
+
+	e.checked.Entry = ent
+	e.checked.ErrorOutput = zapcore.Lock(os.Stderr)
+	e.checked.Write(fields...)
+	return nil
+}
+
+func (*sampledEntry) Sync() error { return nil }
+
+func inSampledTrace(fields []zapcore.Field) bool {
+	for _, field := range fields {
+		if field.Key == fieldTraceSampled && field.Type == zapcore.BoolType {
+			return field.Integer == 1
+		}
+	}
+
+	return false
+}
+
+// RegisterSamplingMetrics exports the dropped entries as wasabi_log_dropped_total.
+func RegisterSamplingMetrics(params SamplingMetricsParams) error {
+	if params.Sampler == nil || params.Registerer == nil {
+		return nil
+	}
+
+	for lvl := zapcore.DebugLevel; lvl <= zapcore.FatalLevel; lvl++ {
+		err := params.Registerer.Register(prometheus.NewCounterFunc(
+			prometheus.CounterOpts{
+				Name:        "wasabi_log_dropped_total",
+				Help:        "Number of log entries dropped by sampling",
+				ConstLabels: prometheus.Labels{"level": lvl.String()},
+			},
+			func() float64 { return float64(params.Sampler.Dropped(lvl)) },
+		))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// newSampledLogger logs to an error only and a debug output, keeping the
+// first entry of each message per minute.
+func newSampledLogger(t *testing.T) (lgr *zap.Logger, errorsOnly, everything *bytes.Buffer) {
+	t.Helper()
+
+	sampler, err := NewSampler(Config{Sampling: SamplingConfig{Enable: true, Tick: time.Minute, Initial: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errorsOnly, everything = &bytes.Buffer{}, &bytes.Buffer{}
+	encoder := zapcore.NewJSONEncoder(encoderConfig())
+	tee := zapcore.NewTee(
+		zapcore.NewCore(encoder, zapcore.AddSync(errorsOnly), zapcore.ErrorLevel),
+		zapcore.NewCore(encoder, zapcore.AddSync(everything), zapcore.DebugLevel),
+	)
+
+	return zap.New(sampler.wrap(tee)), errorsOnly, everything
+}
+
+func sampledContext(t *testing.T) context.Context {
+	t.Helper()
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSampler(sdktrace.AlwaysSample()))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "test")
+	t.Cleanup(func() { span.End() })
+
+	return ctx
+}
+
+func TestSamplingKeepsOutputLevels(t *testing.T) {
+	lgr, errorsOnly, everything := newSampledLogger(t)
+
+	lgr.Info("request served")
+	lgr.Debug("cache miss")
+	lgr.Error("request failed")
+
+	if strings.Contains(errorsOnly.String(), "request served") || strings.Contains(errorsOnly.String(), "cache miss") {
+		t.Errorf("error output received lower levels:\n%s", errorsOnly.String())
+	}
+	if !strings.Contains(errorsOnly.String(), "request failed") {
+		t.Errorf("error output lost the error:\n%s", errorsOnly.String())
+	}
+	if strings.Count(everything.String(), "\n") != 3 {
+		t.Errorf("debug output should hold every entry:\n%s", everything.String())
+	}
+}
+
+func TestSamplingDropsRepeatedErrors(t *testing.T) {
+	lgr, errorsOnly, _ := newSampledLogger(t)
+
+	lgr.Error("request failed")
+	lgr.Error("request failed")
+
+	if n := strings.Count(errorsOnly.String(), "request failed"); n != 1 {
+		t.Errorf("expected 1 sampled error, got %d:\n%s", n, errorsOnly.String())
+	}
+}
+
+func TestSamplingKeepsErrorsOfSampledTraceWithCtx(t *testing.T) {
+	zl, errorsOnly, _ := newSampledLogger(t)
+	lgr := otelzap.New(zl)
+	ctx := sampledContext(t)
+
+	for range 3 {
+		Ctx(lgr, ctx).Error("request failed")
+	}
+
+	if n := strings.Count(errorsOnly.String(), "request failed"); n != 3 {
+		t.Errorf("expected every error of the sampled trace, got %d:\n%s", n, errorsOnly.String())
+	}
+}
+
+func TestSamplingKeepsErrorsOfSampledTraceWith(t *testing.T) {
+	zl, errorsOnly, _ := newSampledLogger(t)
+	lgr := zl.With(TraceFields(sampledContext(t))...)
+
+	for range 3 {
+		lgr.Error("request failed")
+	}
+
+	if n := strings.Count(errorsOnly.String(), "request failed"); n != 3 {
+		t.Errorf("expected every error of the sampled trace, got %d:\n%s", n, errorsOnly.String())
+	}
+}
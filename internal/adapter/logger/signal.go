@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+type (
+	// SignalParams holds the dependencies of HookSignals.
+	SignalParams struct {
+		fx.In
+
+		Lifecycle fx.Lifecycle
+		Config    Config
+		Levels    *Levels
+		Logger    *otelzap.Logger
+	}
+
+	// levelsFile is the content of Config.LevelsFile.
+	levelsFile struct {
+		Level     string            `json:"level"`
+		Overrides map[string]string `json:"overrides"`
+	}
+)
+
+// HookSignals toggles the level between the configured one and debug on
+// the toggle signal (SIGUSR1), and applies the levels of Config.LevelsFile
+// on the reload signal (SIGHUP). Signals are not supported on every platform.
+func HookSignals(params SignalParams) {
+	if len(toggleSignals) == 0 {
+		return
+	}
+
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+
+	params.Lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			signal.Notify(signals, append(toggleSignals, reloadSignals...)...)
+			go params.handleSignals(signals, done)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			signal.Stop(signals)
+			close(done)
+			return nil
+		},
+	})
+}
+
+func (params SignalParams) handleSignals(signals <-chan os.Signal, done <-chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		case sig := <-signals:
+			if isReloadSignal(sig) {
+				params.reload()
+				continue
+			}
+
+			lvl := params.Levels.Toggle()
+			params.Logger.Info("log level toggled", zap.Stringer("level", lvl))
+		}
+	}
+}
+
+func (params SignalParams) reload() {
+	if params.Config.LevelsFile == "" {
+		params.Logger.Warn("log levels not reloaded: no levels file configured")
+		return
+	}
+
+	cfg, err := readLevelsFile(params.Config)
+	if err == nil {
+		err = params.Levels.Apply(cfg)
+	}
+	if err != nil {
+		params.Logger.Error("failed to reload log levels", zap.Error(err))
+		return
+	}
+
+	params.Logger.Info("log levels reloaded", zap.String("level", cfg.Level))
+}
+
+// readLevelsFile returns cfg with the level and overrides of its
+// LevelsFile. The configured level is kept when the file sets none.
+func readLevelsFile(cfg Config) (Config, error) {
+	data, err := os.ReadFile(cfg.LevelsFile)
+	if err != nil {
+		return cfg, err
+	}
+
+	var file levelsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return cfg, fmt.Errorf("parse %s: %w", cfg.LevelsFile, err)
+	}
+
+	if file.Level != "" {
+		cfg.Level = file.Level
+	}
+	cfg.Overrides = file.Overrides
+
+	return cfg, nil
+}
+
+func isReloadSignal(sig os.Signal) bool {
+	for _, reload := range reloadSignals {
+		if sig == reload {
+			return true
+		}
+	}
+
+	return false
+}
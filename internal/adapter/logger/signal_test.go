@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadLevelsFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantLevel string
+		wantGorm  string
+		wantErr   bool
+	}{
+		{
+			name:      "level and overrides",
+			content:   `{"level":"debug","overrides":{"gorm":"warn"}}`,
+			wantLevel: "debug",
+			wantGorm:  "warn",
+		},
+		{
+			name:      "keeps the configured level",
+			content:   `{"overrides":{"gorm":"error"}}`,
+			wantLevel: "info",
+			wantGorm:  "error",
+		},
+		{
+			name:    "invalid json",
+			content: `level=debug`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "levels.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := readLevelsFile(Config{Level: "info", LevelsFile: path})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readLevelsFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if cfg.Level != tt.wantLevel || cfg.Overrides["gorm"] != tt.wantGorm {
+				t.Errorf("readLevelsFile() = %q %v, want %q gorm:%q", cfg.Level, cfg.Overrides, tt.wantLevel, tt.wantGorm)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package logger
+
+import (
+	"os"
+	"syscall"
+)
+
+var (
+	toggleSignals = []os.Signal{syscall.SIGUSR1}
+	reloadSignals = []os.Signal{syscall.SIGHUP}
+)
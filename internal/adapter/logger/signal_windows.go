@@ -0,0 +1,10 @@
+//go:build windows
+
+package logger
+
+import "os"
+
+var (
+	toggleSignals []os.Signal
+	reloadSignals []os.Signal
+)
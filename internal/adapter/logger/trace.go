@@ -0,0 +1,29 @@
+package logger
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	fieldTraceID      = "trace_id"
+	fieldSpanID       = "span_id"
+	fieldTraceSampled = "trace_sampled"
+)
+
+// TraceFields returns the trace correlation fields of the span in ctx,
+// or nothing when ctx carries no valid span.
+func TraceFields(ctx context.Context) []zap.Field {
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.IsValid() {
+		return nil
+	}
+
+	return []zap.Field{
+		zap.String(fieldTraceID, spanCtx.TraceID().String()),
+		zap.String(fieldSpanID, spanCtx.SpanID().String()),
+		zap.Bool(fieldTraceSampled, spanCtx.IsSampled()),
+	}
+}
@@ -2,10 +2,16 @@ package metrics
 
 import (
 	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
+	"fmt"
 	"net/http"
+	"os"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/uptrace/opentelemetry-go-extra/otelzap"
 	"go.uber.org/fx"
@@ -15,10 +21,39 @@ import (
 // ReadHeaderTimeout is the maximum amount of time to allow reading request headers.
 const ReadHeaderTimeout = 5
 
+var ErrInvalidClientCA = errors.New("no certificate found in metrics client CA file")
+
 type (
 	// Config is the configuration for the metrics server.
 	Config struct {
-		Addr string `mapstructure:"addr"`
+		Addr      string          `mapstructure:"addr"`
+		Path      string          `envconfig:"path" default:"/metrics"`
+		BasicAuth BasicAuthConfig `envconfig:"basic_auth"`
+		TLS       TLSConfig       `envconfig:"tls"`
+		OTLP      OTLPConfig      `envconfig:"otlp"`
+	}
+
+	// BasicAuthConfig protects the metrics endpoint with basic auth when Username is set.
+	BasicAuthConfig struct {
+		Username string `envconfig:"username"`
+		Password string `envconfig:"password"`
+	}
+
+	// TLSConfig serves the metrics endpoint over TLS when CertFile is set,
+	// and requires client certificates signed by ClientCAFile when it is set.
+	TLSConfig struct {
+		CertFile     string `envconfig:"cert_file"`
+		KeyFile      string `envconfig:"key_file"`
+		ClientCAFile string `envconfig:"client_ca_file"`
+	}
+
+	// OTLPConfig is the configuration for pushing otel metrics to a collector.
+	OTLPConfig struct {
+		Enable   bool          `envconfig:"enable"`
+		Addr     string        `envconfig:"addr"`
+		Secure   bool          `envconfig:"secure"`
+		Timeout  time.Duration `envconfig:"timeout" default:"10s"`
+		Interval time.Duration `envconfig:"interval" default:"60s"`
 	}
 
 	// Server is an alias for http.Server.
@@ -28,17 +63,42 @@ type (
 // Module is the fx module for the metrics server.
 var Module = fx.Module(
 	"metrics",
+	fx.Provide(NewRegistry),
+	fx.Provide(NewRegisterer),
+	fx.Provide(NewGatherer),
 	fx.Provide(NewServer),
+	fx.Provide(NewMeterProvider),
+	fx.Provide(provideMeter),
 	fx.Invoke(HookMetricsHandler),
+	fx.Invoke(HookMeterProvider),
 )
 
-// NewServer creates a new metrics server.
-func NewServer(c Config) *Server {
-	http.Handle("/metrics", promhttp.Handler())
+// NewServer creates a new metrics server serving the registry on its own mux.
+func NewServer(c Config, registry *prometheus.Registry) (*Server, error) {
+	path := c.Path
+	if path == "" {
+		path = "/metrics"
+	}
+
+	var handler http.Handler = promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+	if c.BasicAuth.Username != "" {
+		handler = basicAuth(handler, c.BasicAuth)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(path, handler)
+
+	tlsConfig, err := newTLSConfig(c.TLS)
+	if err != nil {
+		return nil, err
+	}
+
 	return &http.Server{
 		Addr:              c.Addr,
+		Handler:           mux,
+		TLSConfig:         tlsConfig,
 		ReadHeaderTimeout: ReadHeaderTimeout * time.Second,
-	}
+	}, nil
 }
 
 // HookMetricsHandler hooks the metrics server to the fx lifecycle.
@@ -46,12 +106,18 @@ func HookMetricsHandler(lifecycle fx.Lifecycle, server *Server, logger *otelzap.
 	lifecycle.Append(fx.Hook{
 		OnStart: func(context.Context) error {
 			go func() {
-				err := server.ListenAndServe()
+				var err error
+				if server.TLSConfig != nil {
+					// Certificates are already loaded into TLSConfig.
+					err = server.ListenAndServeTLS("", "")
+				} else {
+					err = server.ListenAndServe()
+				}
 				if err != nil && !errors.Is(err, http.ErrServerClosed) {
 					logger.Fatal("failed to start metrics server", zap.Error(err))
 				}
 			}()
-			logger.Info("metrics server started", zap.String("addr", server.Addr))
+			logger.Info("metrics server started", zap.String("addr", server.Addr), zap.Bool("tls", server.TLSConfig != nil))
 			return nil
 		},
 		OnStop: func(ctx context.Context) error {
@@ -59,3 +125,51 @@ func HookMetricsHandler(lifecycle fx.Lifecycle, server *Server, logger *otelzap.
 		},
 	})
 }
+
+func basicAuth(next http.Handler, cfg BasicAuthConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok ||
+			subtle.ConstantTimeCompare([]byte(username), []byte(cfg.Username)) != 1 ||
+			subtle.ConstantTimeCompare([]byte(password), []byte(cfg.Password)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="metrics"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func newTLSConfig(cfg TLSConfig) (*tls.Config, error) {
+	if cfg.CertFile == "" {
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load metrics tls key pair: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read metrics client ca: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, ErrInvalidClientCA
+		}
+
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/widnyana/wasabi/internal/adapter/buildinfo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	otelprom "go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.uber.org/fx"
+)
+
+// NewMeterProvider creates the OpenTelemetry MeterProvider and installs it globally,
+// so otel-instrumented libraries (redis, otelfiber, gorm) stop writing to the no-op provider.
+// Measurements are bridged into the Prometheus registry served on /metrics and,
+// when enabled, pushed to the OTLP collector.
+func NewMeterProvider(c Config, registry *prometheus.Registry) (*sdkmetric.MeterProvider, error) {
+	promExporter, err := otelprom.New(otelprom.WithRegisterer(registry))
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []sdkmetric.Option{
+		sdkmetric.WithReader(promExporter),
+		sdkmetric.WithResource(buildinfo.Resource()),
+	}
+
+	if c.OTLP.Enable {
+		grpcOpts := []otlpmetricgrpc.Option{
+			otlpmetricgrpc.WithEndpoint(c.OTLP.Addr),
+			otlpmetricgrpc.WithTimeout(c.OTLP.Timeout),
+		}
+
+		if !c.OTLP.Secure {
+			grpcOpts = append(grpcOpts, otlpmetricgrpc.WithInsecure())
+		}
+
+		// The gRPC connection is established lazily, the context only bounds setup.
+		otlpExporter, err := otlpmetricgrpc.New(context.Background(), grpcOpts...)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, sdkmetric.WithReader(
+			sdkmetric.NewPeriodicReader(otlpExporter, sdkmetric.WithInterval(c.OTLP.Interval)),
+		))
+	}
+
+	provider := sdkmetric.NewMeterProvider(opts...)
+	otel.SetMeterProvider(provider)
+
+	return provider, nil
+}
+
+// HookMeterProvider flushes and shuts down the MeterProvider when the app stops.
+func HookMeterProvider(lifecycle fx.Lifecycle, provider *sdkmetric.MeterProvider) {
+	lifecycle.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return provider.Shutdown(ctx)
+		},
+	})
+}
+
+// provideMeter provides the application meter.
+func provideMeter(provider *sdkmetric.MeterProvider) metric.Meter {
+	return provider.Meter("wasabi")
+}
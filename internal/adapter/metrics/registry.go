@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// NewRegistry creates the Prometheus registry served by the metrics server,
+// pre-populated with the Go runtime, process and build info collectors.
+// Use it instead of the global default registry.
+func NewRegistry() (*prometheus.Registry, error) {
+	registry := prometheus.NewRegistry()
+
+	for _, collector := range []prometheus.Collector{
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewBuildInfoCollector(),
+	} {
+		if err := registry.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	return registry, nil
+}
+
+// NewRegisterer exposes the registry as a prometheus.Registerer.
+func NewRegisterer(registry *prometheus.Registry) prometheus.Registerer { return registry }
+
+// NewGatherer exposes the registry as a prometheus.Gatherer.
+func NewGatherer(registry *prometheus.Registry) prometheus.Gatherer { return registry }
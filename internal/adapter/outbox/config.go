@@ -0,0 +1,51 @@
+package outbox
+
+import "time"
+
+const (
+	PublisherMemory  = "memory"
+	PublisherRedis   = "redis"
+	PublisherWebhook = "webhook"
+)
+
+type (
+	// Config represents the configuration of the outbox relay.
+	Config struct {
+		// Enable runs the relay. Events can be added to the outbox either way.
+		Enable bool `envconfig:"enable"`
+		// Publisher is where events are delivered: memory, redis or webhook.
+		Publisher    string        `envconfig:"publisher" default:"memory"`
+		PollInterval time.Duration `envconfig:"poll_interval" default:"1s"`
+		BatchSize    int           `envconfig:"batch_size" default:"100"`
+		// LeaseTimeout is how long a claimed batch is reserved for its
+		// delivery. Events still unpublished after it are claimed again.
+		LeaseTimeout time.Duration `envconfig:"lease_timeout" default:"5m"`
+		// MaxAttempts is how many deliveries are tried before an event is
+		// marked dead. Dead events no longer hold back their aggregate.
+		MaxAttempts     int           `envconfig:"max_attempts" default:"10"`
+		RetryBackoff    time.Duration `envconfig:"retry_backoff" default:"1s"`
+		MaxRetryBackoff time.Duration `envconfig:"max_retry_backoff" default:"5m"`
+		// LeaderRetry is how often a standby instance tries to become the relay.
+		LeaderRetry time.Duration `envconfig:"leader_retry" default:"5s"`
+		// Retention is how long published events are kept. Zero keeps them.
+		Retention time.Duration `envconfig:"retention" default:"168h"`
+
+		Redis   RedisConfig   `envconfig:"redis"`
+		Webhook WebhookConfig `envconfig:"webhook"`
+	}
+
+	// RedisConfig configures the Redis Streams publisher.
+	RedisConfig struct {
+		Stream string `envconfig:"stream" default:"outbox"`
+		// MaxLen approximately caps the stream length. Zero leaves it unbounded.
+		MaxLen int64 `envconfig:"max_len" default:"100000"`
+	}
+
+	// WebhookConfig configures the HTTP webhook publisher.
+	WebhookConfig struct {
+		URL string `envconfig:"url"`
+		// Secret signs the body with HMAC-SHA256 in the X-Outbox-Signature header.
+		Secret  string        `envconfig:"secret"`
+		Timeout time.Duration `envconfig:"timeout" default:"10s"`
+	}
+)
@@ -0,0 +1,12 @@
+package outbox
+
+import "errors"
+
+var (
+	ErrNoTransaction     = errors.New("outbox events must be added inside a transaction")
+	ErrUnknownPublisher  = errors.New("unknown outbox publisher")
+	ErrRedisDisabled     = errors.New("outbox redis publisher requires redis to be enabled")
+	ErrWebhookURLMissing = errors.New("outbox webhook publisher requires a url")
+	ErrWebhookStatus     = errors.New("outbox webhook rejected the event")
+	ErrInvalidInterval   = errors.New("outbox relay intervals must be positive")
+)
@@ -0,0 +1,44 @@
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Event is a domain event stored in the outbox until it is published.
+type Event struct {
+	ID            int64             `gorm:"primaryKey" json:"id"`
+	AggregateType string            `gorm:"not null" json:"aggregate_type"`
+	AggregateID   string            `gorm:"not null" json:"aggregate_id"`
+	Type          string            `gorm:"not null" json:"type"`
+	Payload       json.RawMessage   `gorm:"type:jsonb;not null" json:"payload"`
+	Headers       map[string]string `gorm:"serializer:json" json:"headers,omitempty"`
+	CreatedAt     time.Time         `gorm:"not null" json:"created_at"`
+	Attempts      int               `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time         `gorm:"not null" json:"-"`
+	LastError     string            `json:"-"`
+	PublishedAt   *time.Time        `json:"-"`
+	DeadAt        *time.Time        `json:"-"`
+}
+
+// TableName implements gorm's tabler interface.
+func (Event) TableName() string { return "outbox_events" }
+
+// NewEvent creates an event of eventType about an aggregate, with payload
+// encoded as JSON.
+func NewEvent(aggregateType, aggregateID, eventType string, payload any) (Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Type:          eventType,
+		Payload:       data,
+	}, nil
+}
+
+// aggregateKey identifies the aggregate whose events are delivered in order.
+func (event Event) aggregateKey() string { return event.AggregateType + "\x00" + event.AggregateID }
@@ -0,0 +1,68 @@
+package outbox
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const lblType = "type"
+
+// Metrics records the deliveries of the relay.
+type Metrics struct {
+	published *prometheus.CounterVec
+	failures  *prometheus.CounterVec
+	dead      *prometheus.CounterVec
+	duration  prometheus.Histogram
+	pending   prometheus.Gauge
+	oldest    prometheus.Gauge
+	leader    prometheus.Gauge
+}
+
+// NewMetrics creates a new Metrics registering its metrics into registerer.
+func NewMetrics(registerer prometheus.Registerer) *Metrics {
+	factory := promauto.With(registerer)
+
+	return &Metrics{
+		published: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "outbox_events_published_total",
+			Help: "Number of outbox events published",
+		}, []string{lblType}),
+		failures: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "outbox_publish_failures_total",
+			Help: "Number of failed outbox event deliveries",
+		}, []string{lblType}),
+		dead: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "outbox_events_dead_total",
+			Help: "Number of outbox events given up after max_attempts deliveries",
+		}, []string{lblType}),
+		duration: factory.NewHistogram(prometheus.HistogramOpts{
+			Name:    "outbox_publish_duration_seconds",
+			Help:    "Duration of outbox event deliveries in seconds",
+			Buckets: prometheus.DefBuckets,
+		}),
+		pending: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "outbox_pending_events",
+			Help: "Number of outbox events waiting to be published",
+		}),
+		oldest: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "outbox_oldest_pending_age_seconds",
+			Help: "Age of the oldest outbox event waiting to be published",
+		}),
+		leader: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "outbox_relay_leader",
+			Help: "Whether this instance runs the outbox relay",
+		}),
+	}
+}
+
+func (m *Metrics) observe(event Event, elapsed time.Duration, err error) {
+	m.duration.Observe(elapsed.Seconds())
+	if err != nil {
+		m.failures.WithLabelValues(event.Type).Inc()
+		return
+	}
+
+	m.published.WithLabelValues(event.Type).Inc()
+}
@@ -0,0 +1,65 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/widnyana/wasabi/internal/adapter/database/pg/notify"
+	"go.uber.org/fx"
+)
+
+// wakeChannel is notified by a trigger whenever events are added.
+const wakeChannel = "outbox_events"
+
+var (
+	Module = fx.Module("outbox", Providers, Invokers)
+
+	Providers = fx.Options(
+		fx.Provide(NewOutbox),
+		fx.Provide(NewPublisher),
+		fx.Provide(NewMetrics),
+		fx.Provide(NewRelay),
+		fx.Provide(notify.AsHandler(NewWakeHandler)),
+	)
+
+	Invokers = fx.Options(
+		fx.Invoke(HookRelay),
+	)
+)
+
+// HookRelay runs the relay in the background when enabled. It stops with the
+// application context, or on stop at the latest.
+func HookRelay(lifecycle fx.Lifecycle, appCtx context.Context, cfg Config, relay *Relay) {
+	if !cfg.Enable {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(appCtx)
+	done := make(chan struct{})
+
+	lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				relay.Run(ctx)
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			<-done
+			return nil
+		},
+	})
+}
+
+// NewWakeHandler wakes the relay up as soon as events are committed, when the
+// notification listener runs. Without it the relay still polls.
+func NewWakeHandler(relay *Relay) notify.Handler {
+	return notify.Handler{
+		Channel: wakeChannel,
+		Handle: func(context.Context, string) error {
+			relay.Wake()
+			return nil
+		},
+	}
+}
@@ -0,0 +1,53 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/widnyana/wasabi/internal/adapter/database/pg"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"gorm.io/gorm"
+)
+
+// Outbox stores events in the transaction of the state change they describe,
+// so they are published if and only if that transaction commits.
+type Outbox struct {
+	tx *pg.TxManager
+}
+
+// NewOutbox creates a new Outbox.
+func NewOutbox(tx *pg.TxManager) *Outbox {
+	return &Outbox{tx: tx}
+}
+
+// Add stores events in the transaction carried by ctx, as started by
+// pg.TxManager.Do. It fails with ErrNoTransaction outside of a transaction.
+func (o *Outbox) Add(ctx context.Context, events ...Event) error {
+	if !pg.InTx(ctx) {
+		return ErrNoTransaction
+	}
+
+	return AddWith(ctx, o.tx.DB(ctx), events...)
+}
+
+// AddWith stores events through tx, a transaction managed by the caller.
+// The trace context of ctx travels with the events to the publisher.
+func AddWith(ctx context.Context, tx *gorm.DB, events ...Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	for i := range events {
+		events[i].ID = 0
+		events[i].CreatedAt = now
+		events[i].NextAttemptAt = now
+		if events[i].Headers == nil {
+			events[i].Headers = map[string]string{}
+		}
+		otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(events[i].Headers))
+	}
+
+	return tx.WithContext(ctx).Create(&events).Error
+}
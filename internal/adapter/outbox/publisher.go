@@ -0,0 +1,96 @@
+package outbox
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	grds "github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+)
+
+type (
+	// Publisher delivers events to the outside world. Delivery is at least
+	// once: an event may be published again when its outbox row cannot be
+	// marked afterwards, so consumers should deduplicate on Event.ID.
+	Publisher interface {
+		Publish(ctx context.Context, event Event) error
+	}
+
+	// PublisherParams holds the backends a Publisher can be built from.
+	PublisherParams struct {
+		fx.In
+
+		Config Config
+		Redis  *grds.Client `optional:"true"`
+	}
+
+	// MemoryPublisher keeps published events in memory. It is meant for tests.
+	MemoryPublisher struct {
+		mu     sync.Mutex
+		events []Event
+		err    error
+	}
+)
+
+// NewPublisher returns the publisher selected by Config.Publisher.
+func NewPublisher(params PublisherParams) (Publisher, error) {
+	switch params.Config.Publisher {
+	case PublisherMemory, "":
+		return NewMemoryPublisher(), nil
+	case PublisherRedis:
+		if params.Redis == nil {
+			return nil, ErrRedisDisabled
+		}
+		return NewRedisPublisher(params.Redis, params.Config.Redis), nil
+	case PublisherWebhook:
+		if params.Config.Webhook.URL == "" {
+			return nil, ErrWebhookURLMissing
+		}
+		return NewWebhookPublisher(&http.Client{Timeout: params.Config.Webhook.Timeout}, params.Config.Webhook), nil
+	default:
+		return nil, ErrUnknownPublisher
+	}
+}
+
+// NewMemoryPublisher creates an empty MemoryPublisher.
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+// Publish implements Publisher.
+func (publisher *MemoryPublisher) Publish(_ context.Context, event Event) error {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	if publisher.err != nil {
+		return publisher.err
+	}
+
+	publisher.events = append(publisher.events, event)
+	return nil
+}
+
+// Events returns the published events in publication order.
+func (publisher *MemoryPublisher) Events() []Event {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	return append([]Event(nil), publisher.events...)
+}
+
+// FailWith makes Publish return err until it is called again with nil.
+func (publisher *MemoryPublisher) FailWith(err error) {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	publisher.err = err
+}
+
+// Reset drops the published events.
+func (publisher *MemoryPublisher) Reset() {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	publisher.events = nil
+}
@@ -0,0 +1,43 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	grds "github.com/redis/go-redis/v9"
+)
+
+// RedisPublisher appends events to a Redis stream.
+type RedisPublisher struct {
+	client *grds.Client
+	cfg    RedisConfig
+}
+
+// NewRedisPublisher creates a new RedisPublisher.
+func NewRedisPublisher(client *grds.Client, cfg RedisConfig) *RedisPublisher {
+	return &RedisPublisher{client: client, cfg: cfg}
+}
+
+// Publish implements Publisher.
+func (publisher *RedisPublisher) Publish(ctx context.Context, event Event) error {
+	headers, err := json.Marshal(event.Headers)
+	if err != nil {
+		return err
+	}
+
+	return publisher.client.XAdd(ctx, &grds.XAddArgs{
+		Stream: publisher.cfg.Stream,
+		MaxLen: publisher.cfg.MaxLen,
+		Approx: publisher.cfg.MaxLen > 0,
+		Values: map[string]any{
+			"id":             strconv.FormatInt(event.ID, 10),
+			"aggregate_type": event.AggregateType,
+			"aggregate_id":   event.AggregateID,
+			"type":           event.Type,
+			"payload":        string(event.Payload),
+			"headers":        string(headers),
+			"created_at":     event.CreatedAt.Format(timeFormat),
+		},
+	}).Err()
+}
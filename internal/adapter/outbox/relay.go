@@ -0,0 +1,303 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/opentelemetry-go-extra/otelzap"
+	"github.com/widnyana/wasabi/internal/adapter/database/pg"
+	applog "github.com/widnyana/wasabi/internal/adapter/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"gorm.io/gorm/clause"
+)
+
+// pendingEvents restricts a query to the events still to be published.
+const pendingEvents = "published_at IS NULL AND dead_at IS NULL"
+
+// notBehindRetry excludes the events whose aggregate has an earlier event
+// waiting for a retry, which keeps the delivery order per aggregate.
+const notBehindRetry = `NOT EXISTS (
+	SELECT 1 FROM outbox_events AS earlier
+	WHERE earlier.aggregate_type = outbox_events.aggregate_type
+	  AND earlier.aggregate_id = outbox_events.aggregate_id
+	  AND earlier.id < outbox_events.id
+	  AND earlier.published_at IS NULL AND earlier.dead_at IS NULL
+	  AND earlier.next_attempt_at > ?)`
+
+// Relay publishes the events of the outbox. Only the leader instance relays,
+// and on top of it batches are leased in a FOR UPDATE SKIP LOCKED
+// transaction, so an event is never delivered by two instances at once.
+// Delivery is at least once: an event whose delivery could not be recorded
+// is delivered again after its lease expires.
+type Relay struct {
+	cfg       Config
+	tx        *pg.TxManager
+	publisher Publisher
+	leader    *pg.Leader
+	metrics   *Metrics
+	tracer    trace.Tracer
+	logger    *otelzap.Logger
+	wake      chan struct{}
+}
+
+// NewRelay creates a new Relay. It fails on a non-positive PollInterval or
+// LeaderRetry.
+func NewRelay(
+	cfg Config,
+	tx *pg.TxManager,
+	sqlDB *sql.DB,
+	publisher Publisher,
+	metrics *Metrics,
+	logger *otelzap.Logger,
+) (*Relay, error) {
+	if cfg.PollInterval <= 0 {
+		return nil, fmt.Errorf("%w: poll_interval %s", ErrInvalidInterval, cfg.PollInterval)
+	}
+
+	leader, err := pg.NewLeader(sqlDB, "outbox", cfg.LeaderRetry, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Relay{
+		cfg:       cfg,
+		tx:        tx,
+		publisher: publisher,
+		leader:    leader,
+		metrics:   metrics,
+		tracer:    otel.Tracer("outbox"),
+		logger:    logger,
+		wake:      make(chan struct{}, 1),
+	}, nil
+}
+
+// Wake makes the relay poll now instead of at the next interval, e.g. when
+// notified of a new event.
+func (r *Relay) Wake() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run relays events whenever this instance is the leader, until ctx is done.
+func (r *Relay) Run(ctx context.Context) {
+	r.leader.Run(ctx, r.relay)
+}
+
+func (r *Relay) relay(ctx context.Context) {
+	r.metrics.leader.Set(1)
+	defer r.metrics.leader.Set(0)
+
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := r.RelayOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			applog.Ctx(r.logger, ctx).Error("failed to relay outbox events", zap.Error(err))
+		}
+
+		r.maintain(ctx)
+
+		// A full batch means more events are probably waiting.
+		if err == nil && n == r.cfg.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.wake:
+		}
+	}
+}
+
+// RelayOnce publishes one batch of pending events and returns how many were
+// claimed. Events of an aggregate whose delivery failed are left for later.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	events, err := r.claim(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	failed := map[string]struct{}{}
+	for _, event := range events {
+		if _, ok := failed[event.aggregateKey()]; ok {
+			errs = append(errs, r.release(ctx, event))
+			continue
+		}
+
+		if err := r.publish(ctx, event); err != nil {
+			failed[event.aggregateKey()] = struct{}{}
+			errs = append(errs, r.retry(ctx, event, err))
+			continue
+		}
+
+		if err := r.markPublished(ctx, event); err != nil {
+			// Holding the aggregate back keeps the redelivery in order.
+			failed[event.aggregateKey()] = struct{}{}
+			errs = append(errs, err)
+		}
+	}
+
+	return len(events), errors.Join(errs...)
+}
+
+// claim leases a batch of pending events in a short transaction, so that
+// they are published without holding row locks. The lease keeps later events
+// of the same aggregates back until the batch is done.
+func (r *Relay) claim(ctx context.Context) ([]Event, error) {
+	var events []Event
+
+	// Conflicting claims are left to the next poll rather than retried.
+	err := r.tx.Do(ctx, func(ctx context.Context) error {
+		db := r.tx.DB(ctx)
+		now := time.Now().UTC()
+
+		err := db.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where(pendingEvents).
+			Where("next_attempt_at <= ?", now).
+			Where(notBehindRetry, now).
+			Order("id").
+			Limit(r.cfg.BatchSize).
+			Find(&events).Error
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		ids := make([]int64, len(events))
+		for i, event := range events {
+			ids[i] = event.ID
+		}
+
+		return db.Model(&Event{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(r.cfg.LeaseTimeout)).Error
+	}, pg.WithRetries(0))
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// markPublished records the delivery of event. Should it fail, the event is
+// delivered again once its lease expires.
+func (r *Relay) markPublished(ctx context.Context, event Event) error {
+	return r.tx.DB(ctx).Model(&Event{}).
+		Where("id = ?", event.ID).
+		Update("published_at", time.Now().UTC()).Error
+}
+
+// release ends the lease of an event that was claimed but not attempted.
+func (r *Relay) release(ctx context.Context, event Event) error {
+	return r.tx.DB(ctx).Model(&Event{}).
+		Where("id = ?", event.ID).
+		Update("next_attempt_at", time.Now().UTC()).Error
+}
+
+func (r *Relay) publish(ctx context.Context, event Event) error {
+	// The span is linked to the trace that added the event.
+	origin := trace.SpanContextFromContext(
+		otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(event.Headers)))
+
+	ctx, span := r.tracer.Start(ctx, "outbox.publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithLinks(trace.Link{SpanContext: origin}),
+		trace.WithAttributes(
+			attribute.Int64("outbox.event.id", event.ID),
+			attribute.String("outbox.event.type", event.Type),
+			attribute.String("outbox.aggregate.type", event.AggregateType),
+			attribute.Int("outbox.event.attempts", event.Attempts),
+		),
+	)
+	defer span.End()
+
+	begin := time.Now()
+	err := r.publisher.Publish(ctx, event)
+	r.metrics.observe(event, time.Since(begin), err)
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
+
+// retry schedules the next delivery of event, or marks it dead once
+// MaxAttempts is reached.
+func (r *Relay) retry(ctx context.Context, event Event, cause error) error {
+	attempts := event.Attempts + 1
+	now := time.Now().UTC()
+	updates := map[string]any{"attempts": attempts, "last_error": cause.Error()}
+
+	lgr := applog.Ctx(r.logger, ctx)
+	fields := []zap.Field{
+		zap.Int64("event_id", event.ID),
+		zap.String("event_type", event.Type),
+		zap.Int("attempts", attempts),
+		zap.Error(cause),
+	}
+
+	if attempts >= r.cfg.MaxAttempts {
+		updates["dead_at"] = now
+		r.metrics.dead.WithLabelValues(event.Type).Inc()
+		lgr.Error("outbox event dead after max attempts", fields...)
+	} else {
+		backoff := r.backoff(attempts)
+		updates["next_attempt_at"] = now.Add(backoff)
+		lgr.Warn("outbox event delivery failed", append(fields, zap.Duration("backoff", backoff))...)
+	}
+
+	return r.tx.DB(ctx).Model(&Event{}).Where("id = ?", event.ID).Updates(updates).Error
+}
+
+func (r *Relay) backoff(attempts int) time.Duration {
+	backoff := r.cfg.RetryBackoff
+	for i := 1; i < attempts && backoff < r.cfg.MaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, r.cfg.MaxRetryBackoff)
+}
+
+// maintain refreshes the backlog metrics and drops the published events
+// older than Retention.
+func (r *Relay) maintain(ctx context.Context) {
+	db := r.tx.DB(ctx)
+
+	var backlog struct {
+		Pending int64
+		Oldest  sql.NullTime
+	}
+	err := db.Model(&Event{}).
+		Select("count(*) AS pending, min(created_at) AS oldest").
+		Where(pendingEvents).
+		Scan(&backlog).Error
+	if err == nil {
+		r.metrics.pending.Set(float64(backlog.Pending))
+		r.metrics.oldest.Set(0)
+		if backlog.Oldest.Valid {
+			r.metrics.oldest.Set(time.Since(backlog.Oldest.Time).Seconds())
+		}
+	}
+
+	if r.cfg.Retention > 0 {
+		err = db.Where("published_at < ?", time.Now().UTC().Add(-r.cfg.Retention)).Delete(&Event{}).Error
+	}
+
+	if err != nil && ctx.Err() == nil {
+		applog.Ctx(r.logger, ctx).Warn("failed to maintain the outbox", zap.Error(err))
+	}
+}
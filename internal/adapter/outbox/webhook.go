@@ -0,0 +1,76 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+const (
+	timeFormat = time.RFC3339Nano
+
+	headerEventID   = "X-Outbox-Event-Id"
+	headerEventType = "X-Outbox-Event-Type"
+	headerSignature = "X-Outbox-Signature"
+)
+
+// WebhookPublisher posts events as JSON to an HTTP endpoint. Any 2xx
+// response acknowledges the event.
+type WebhookPublisher struct {
+	client *http.Client
+	cfg    WebhookConfig
+}
+
+// NewWebhookPublisher creates a new WebhookPublisher.
+func NewWebhookPublisher(client *http.Client, cfg WebhookConfig) *WebhookPublisher {
+	return &WebhookPublisher{client: client, cfg: cfg}
+}
+
+// Publish implements Publisher.
+func (publisher *WebhookPublisher) Publish(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, publisher.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	id := strconv.FormatInt(event.ID, 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", "outbox-"+id)
+	req.Header.Set(headerEventID, id)
+	req.Header.Set(headerEventType, event.Type)
+	if publisher.cfg.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(publisher.cfg.Secret))
+		mac.Write(body)
+		req.Header.Set(headerSignature, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := publisher.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%w: status %d", ErrWebhookStatus, resp.StatusCode)
+	}
+
+	return nil
+}
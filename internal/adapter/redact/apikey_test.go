@@ -0,0 +1,28 @@
+package redact_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/widnyana/wasabi/internal/adapter/auth"
+	"github.com/widnyana/wasabi/internal/adapter/redact"
+)
+
+func TestStringRedactsGeneratedAPIKeys(t *testing.T) {
+	policy, err := redact.NewPolicy(redact.Config{Enable: true, Tokens: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		plain, public, err := auth.GenerateKey("wsb")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := policy.String("authenticated with " + plain)
+		if strings.Contains(got, plain) || strings.Contains(got, public) {
+			t.Fatalf("String() leaks the generated key %q: %q", plain, got)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Config is the redaction policy shared by logs, spans and SQL traces.
+type Config struct {
+	Enable bool `envconfig:"enable" default:"true"`
+	// Fields are case-insensitive patterns matched against field, attribute
+	// and column names. Values of matching names are replaced entirely.
+	Fields []string `envconfig:"fields" default:"password,passwd,secret,token,api_?key,authorization,cookie,pepper,dsn,credential,private_?key,card_?number,cvv,ssn"`
+	// Headers are HTTP header names whose values are always replaced.
+	Headers []string `envconfig:"headers" default:"authorization,proxy-authorization,cookie,set-cookie,x-api-key,x-auth-token"`
+	// Patterns are extra regular expressions, separated by ";", whose
+	// matches are replaced inside any string value.
+	Patterns Patterns `envconfig:"patterns"`
+	Emails   bool     `envconfig:"emails" default:"true"`
+	Cards    bool     `envconfig:"cards" default:"true"`
+	Tokens   bool     `envconfig:"tokens" default:"true"`
+}
+
+// Patterns is a list of regular expressions decoded from a ";" separated string.
+type Patterns []*regexp.Regexp
+
+// Decode implements envconfig.Decoder.
+func (patterns *Patterns) Decode(value string) error {
+	*patterns = nil
+	for _, expr := range strings.Split(value, ";") {
+		expr = strings.TrimSpace(expr)
+		if expr == "" {
+			continue
+		}
+
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("invalid redaction pattern %q: %w", expr, err)
+		}
+		*patterns = append(*patterns, re)
+	}
+
+	return nil
+}
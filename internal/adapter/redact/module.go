@@ -0,0 +1,7 @@
+package redact
+
+import "go.uber.org/fx"
+
+// Module provides the *Policy compiled from Config, used by the logger,
+// the tracer provider and the GORM logger.
+var Module = fx.Module("redact", fx.Provide(NewPolicy))
@@ -0,0 +1,118 @@
+package redact
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+type (
+	spanProcessor struct {
+		next   sdktrace.SpanProcessor
+		policy *Policy
+	}
+
+	// redactedSpan overrides the parts of a span that may carry user data.
+	redactedSpan struct {
+		sdktrace.ReadOnlySpan
+		policy *Policy
+	}
+)
+
+// NewSpanProcessor returns a SpanProcessor that redacts span attributes,
+// events and status before handing ended spans to next. A nil policy
+// returns next unchanged.
+func NewSpanProcessor(policy *Policy, next sdktrace.SpanProcessor) sdktrace.SpanProcessor {
+	if policy == nil {
+		return next
+	}
+
+	return &spanProcessor{next: next, policy: policy}
+}
+
+// OnStart implements sdktrace.SpanProcessor.
+func (processor *spanProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	processor.next.OnStart(parent, s)
+}
+
+// OnEnd implements sdktrace.SpanProcessor.
+func (processor *spanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	processor.next.OnEnd(&redactedSpan{ReadOnlySpan: s, policy: processor.policy})
+}
+
+// Shutdown implements sdktrace.SpanProcessor.
+func (processor *spanProcessor) Shutdown(ctx context.Context) error {
+	return processor.next.Shutdown(ctx)
+}
+
+// ForceFlush implements sdktrace.SpanProcessor.
+func (processor *spanProcessor) ForceFlush(ctx context.Context) error {
+	return processor.next.ForceFlush(ctx)
+}
+
+// Attributes implements sdktrace.ReadOnlySpan.
+func (span *redactedSpan) Attributes() []attribute.KeyValue {
+	return span.policy.attributes(span.ReadOnlySpan.Attributes())
+}
+
+// Events implements sdktrace.ReadOnlySpan.
+func (span *redactedSpan) Events() []sdktrace.Event {
+	events := span.ReadOnlySpan.Events()
+	out := make([]sdktrace.Event, len(events))
+	for i, event := range events {
+		event.Name = span.policy.String(event.Name)
+		event.Attributes = span.policy.attributes(event.Attributes)
+		out[i] = event
+	}
+
+	return out
+}
+
+// Links implements sdktrace.ReadOnlySpan.
+func (span *redactedSpan) Links() []sdktrace.Link {
+	links := span.ReadOnlySpan.Links()
+	out := make([]sdktrace.Link, len(links))
+	for i, link := range links {
+		link.Attributes = span.policy.attributes(link.Attributes)
+		out[i] = link
+	}
+
+	return out
+}
+
+// Status implements sdktrace.ReadOnlySpan.
+func (span *redactedSpan) Status() sdktrace.Status {
+	status := span.ReadOnlySpan.Status()
+	status.Description = span.policy.String(status.Description)
+	return status
+}
+
+func (policy *Policy) attributes(attrs []attribute.KeyValue) []attribute.KeyValue {
+	out := make([]attribute.KeyValue, len(attrs))
+	for i, attr := range attrs {
+		out[i] = policy.attribute(attr)
+	}
+
+	return out
+}
+
+func (policy *Policy) attribute(attr attribute.KeyValue) attribute.KeyValue {
+	key := string(attr.Key)
+	if policy.Field(key) {
+		return attribute.String(key, Redacted)
+	}
+
+	switch attr.Value.Type() {
+	case attribute.STRING:
+		return attribute.String(key, policy.String(attr.Value.AsString()))
+	case attribute.STRINGSLICE:
+		values := attr.Value.AsStringSlice()
+		for i, value := range values {
+			values[i] = policy.String(value)
+		}
+		return attribute.StringSlice(key, values)
+	default:
+		return attr
+	}
+}
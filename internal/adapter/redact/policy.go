@@ -0,0 +1,214 @@
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces every redacted value.
+const Redacted = "[REDACTED]"
+
+var (
+	emailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	cardPattern   = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	tokenPatterns = []*regexp.Regexp{
+		// JSON web tokens.
+		regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+		// API keys in the <prefix>_<id>_<secret> format of the auth package.
+		regexp.MustCompile(`\b[A-Za-z]{2,8}_[0-9a-f]{8}_[A-Za-z0-9_-]{20,}`),
+	}
+	// schemePattern keeps the authorization scheme and redacts the credentials.
+	schemePattern = regexp.MustCompile(`(?i)\b((?:bearer|basic|apikey|token)\s+)[A-Za-z0-9._~+/=-]{8,}`)
+	// userinfoPattern redacts the password of URLs such as DSNs.
+	userinfoPattern = regexp.MustCompile(`(\b[A-Za-z][A-Za-z0-9+.-]*://[^:/@\s]+:)[^@/\s]+@`)
+	placeholder     = regexp.MustCompile(`^\$\d+$|^\?$`)
+)
+
+// Policy is a compiled redaction Config. A nil or disabled Policy redacts nothing.
+type Policy struct {
+	names    *regexp.Regexp
+	assigns  *regexp.Regexp
+	headers  map[string]struct{}
+	patterns []*regexp.Regexp
+	emails   bool
+	cards    bool
+}
+
+// NewPolicy compiles cfg into a Policy.
+func NewPolicy(cfg Config) (*Policy, error) {
+	if !cfg.Enable {
+		return nil, nil
+	}
+
+	policy := &Policy{
+		headers:  map[string]struct{}{},
+		patterns: cfg.Patterns,
+		emails:   cfg.Emails,
+		cards:    cfg.Cards,
+	}
+
+	if len(cfg.Fields) > 0 {
+		expr := "(?i)(?:" + strings.Join(cfg.Fields, "|") + ")"
+		names, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction fields: %w", err)
+		}
+		policy.names = names
+
+		// assigns matches name=value pairs inside free text, DSNs and SQL.
+		policy.assigns = regexp.MustCompile(`(?i)(\b[\w-]*(?:` + strings.Join(cfg.Fields, "|") +
+			`)[\w-]*"?\s*[=:]\s*)("[^"]*"|'[^']*'|[^\s&,;)]+)`)
+	}
+
+	for _, header := range cfg.Headers {
+		policy.headers[strings.ToLower(strings.TrimSpace(header))] = struct{}{}
+	}
+
+	if cfg.Tokens {
+		policy.patterns = append(policy.patterns, tokenPatterns...)
+	}
+
+	return policy, nil
+}
+
+// Field reports whether values named name must be redacted entirely. Dotted
+// names such as http.request.header.authorization are matched on their last
+// segment as well.
+func (policy *Policy) Field(name string) bool {
+	if policy == nil || name == "" {
+		return false
+	}
+
+	last := name
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		last = name[i+1:]
+	}
+
+	lower := strings.ToLower(last)
+	if _, ok := policy.headers[lower]; ok {
+		return true
+	}
+	if _, ok := policy.headers[strings.ReplaceAll(lower, "_", "-")]; ok {
+		return true
+	}
+
+	return policy.names != nil && policy.names.MatchString(name)
+}
+
+// String returns s with secrets, credentials, emails and card numbers replaced.
+func (policy *Policy) String(s string) string {
+	if policy == nil || s == "" {
+		return s
+	}
+
+	s = userinfoPattern.ReplaceAllString(s, "${1}"+Redacted+"@")
+	s = schemePattern.ReplaceAllString(s, "${1}"+Redacted)
+
+	if policy.assigns != nil {
+		s = policy.assigns.ReplaceAllStringFunc(s, func(match string) string {
+			parts := policy.assigns.FindStringSubmatch(match)
+			if placeholder.MatchString(parts[2]) {
+				return match
+			}
+			return parts[1] + Redacted
+		})
+	}
+
+	for _, pattern := range policy.patterns {
+		s = pattern.ReplaceAllString(s, Redacted)
+	}
+
+	if policy.emails {
+		s = emailPattern.ReplaceAllString(s, Redacted)
+	}
+
+	if policy.cards {
+		s = cardPattern.ReplaceAllStringFunc(s, func(match string) string {
+			if luhn(match) {
+				return Redacted
+			}
+			return match
+		})
+	}
+
+	return s
+}
+
+// Error returns err with its message redacted. The original error stays
+// reachable through errors.Is and errors.As.
+func (policy *Policy) Error(err error) error {
+	if policy == nil || err == nil {
+		return err
+	}
+
+	msg := policy.String(err.Error())
+	if msg == err.Error() {
+		return err
+	}
+
+	return &redactedError{msg: msg, err: err}
+}
+
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+
+func (e *redactedError) Unwrap() error { return e.err }
+
+// Value redacts decoded values such as JSON documents: strings are scrubbed
+// and map entries with sensitive keys are replaced.
+func (policy *Policy) Value(value any) any {
+	if policy == nil {
+		return value
+	}
+
+	switch v := value.(type) {
+	case string:
+		return policy.String(v)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			if policy.Field(key) {
+				out[key] = Redacted
+				continue
+			}
+			out[key] = policy.Value(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = policy.Value(item)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+// luhn reports whether the digits of s pass the Luhn checksum, which tells
+// card numbers apart from other long numbers such as timestamps.
+func luhn(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] < '0' || s[i] > '9' {
+			continue
+		}
+
+		digit := int(s[i] - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+
+	return sum%10 == 0
+}
@@ -0,0 +1,76 @@
+package redact
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// comparePattern finds placeholders compared or assigned to a column.
+	comparePattern = regexp.MustCompile(`(?i)"?(\w+)"?\s*(?:=|<>|!=|\bLIKE|\bILIKE)\s*\$(\d+)`)
+	insertPattern  = regexp.MustCompile(`(?is)^\s*INSERT\s+INTO\s+\S+\s*\(([^)]*)\)\s*VALUES\s*(.*)$`)
+	paramPattern   = regexp.MustCompile(`\$(\d+)`)
+)
+
+// SQLParams redacts the bind parameters of a postgres statement. Parameters
+// bound to sensitive columns are replaced entirely, string parameters are
+// scrubbed like any other string.
+func (policy *Policy) SQLParams(sql string, params []any) []any {
+	if policy == nil || len(params) == 0 {
+		return params
+	}
+
+	sensitive := map[int]bool{}
+	for _, match := range comparePattern.FindAllStringSubmatch(sql, -1) {
+		if policy.Field(match[1]) {
+			sensitive[placeholderIndex(match[2])] = true
+		}
+	}
+
+	if match := insertPattern.FindStringSubmatch(sql); match != nil {
+		columns := strings.Split(match[1], ",")
+		for i, param := range paramPattern.FindAllStringSubmatch(match[2], -1) {
+			column := strings.Trim(strings.TrimSpace(columns[i%len(columns)]), `"`)
+			if policy.Field(column) {
+				sensitive[placeholderIndex(param[1])] = true
+			}
+		}
+	}
+
+	out := make([]any, len(params))
+	for i, param := range params {
+		switch v := param.(type) {
+		case string:
+			if sensitive[i] {
+				out[i] = Redacted
+			} else {
+				out[i] = policy.String(v)
+			}
+		case []byte:
+			if sensitive[i] {
+				out[i] = Redacted
+			} else {
+				out[i] = policy.String(string(v))
+			}
+		default:
+			if sensitive[i] {
+				out[i] = Redacted
+			} else {
+				out[i] = param
+			}
+		}
+	}
+
+	return out
+}
+
+// placeholderIndex converts a 1-based $n placeholder into a parameter index.
+func placeholderIndex(n string) int {
+	i, err := strconv.Atoi(n)
+	if err != nil {
+		return -1
+	}
+
+	return i - 1
+}
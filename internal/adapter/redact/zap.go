@@ -0,0 +1,148 @@
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type zapCore struct {
+	zapcore.Core
+	policy *Policy
+}
+
+// Core wraps core so that messages and fields are redacted before they are
+// encoded. A nil policy returns core unchanged.
+func Core(core zapcore.Core, policy *Policy) zapcore.Core {
+	if policy == nil {
+		return core
+	}
+
+	return &zapCore{Core: core, policy: policy}
+}
+
+// With implements zapcore.Core.
+func (core *zapCore) With(fields []zapcore.Field) zapcore.Core {
+	return &zapCore{Core: core.Core.With(core.policy.fields(fields)), policy: core.policy}
+}
+
+// Check implements zapcore.Core. The wrapped core checks the entry itself,
+// so every sink of a tee keeps its own level, and the sinks that accept it
+// receive the redacted entry.
+func (core *zapCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	checked := core.Core.Check(ent, nil)
+	if checked == nil {
+		return ce
+	}
+
+	return ce.AddCore(ent, &redactedEntry{checked: checked, policy: core.policy})
+}
+
+// Write implements zapcore.Core.
+func (core *zapCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = core.policy.String(ent.Message)
+	return core.Core.Write(ent, core.policy.fields(fields))
+}
+
+// redactedEntry writes an entry, once redacted, to the sinks that accepted it.
+type redactedEntry struct {
+	checked *zapcore.CheckedEntry
+	policy  *Policy
+}
+
+func (*redactedEntry) Enabled(zapcore.Level) bool { return true }
+
+func (e *redactedEntry) With([]zapcore.Field) zapcore.Core { return e }
+
+func (*redactedEntry) Check(_ zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce
+}
+
+// Write is called once, with the entry completed by the logger after Check.
+func (e *redactedEntry) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = e.policy.String(ent.Message)
+	e.checked.Entry = ent
+	e.checked.ErrorOutput = zapcore.Lock(os.Stderr)
+	e.checked.Write(e.policy.fields(fields)...)
+	return nil
+}
+
+func (*redactedEntry) Sync() error { return nil }
+
+func (policy *Policy) fields(fields []zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, len(fields))
+	for i, field := range fields {
+		out[i] = policy.field(field)
+	}
+
+	return out
+}
+
+func (policy *Policy) field(field zapcore.Field) zapcore.Field {
+	switch field.Type {
+	case zapcore.SkipType, zapcore.NamespaceType:
+		return field
+	}
+
+	if policy.Field(field.Key) {
+		return zap.String(field.Key, Redacted)
+	}
+
+	switch field.Type {
+	case zapcore.StringType:
+		field.String = policy.String(field.String)
+	case zapcore.ByteStringType:
+		if b, ok := field.Interface.([]byte); ok {
+			return zap.ByteString(field.Key, []byte(policy.String(string(b))))
+		}
+	case zapcore.ErrorType:
+		if err, ok := field.Interface.(error); ok {
+			field.Interface = policy.Error(err)
+		}
+	case zapcore.StringerType:
+		if stringer, ok := field.Interface.(fmt.Stringer); ok && stringer != nil {
+			return zap.String(field.Key, policy.String(stringer.String()))
+		}
+	case zapcore.ReflectType:
+		// Round trip through JSON so nested keys and strings can be inspected.
+		raw, err := json.Marshal(field.Interface)
+		if err != nil {
+			return field
+		}
+		var decoded any
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			return field
+		}
+		return zap.Any(field.Key, policy.Value(decoded))
+	case zapcore.ObjectMarshalerType, zapcore.ArrayMarshalerType:
+		enc := zapcore.NewMapObjectEncoder()
+		field.AddTo(enc)
+		return zap.Any(field.Key, policy.Value(normalize(enc.Fields[field.Key])))
+	}
+
+	return field
+}
+
+// normalize converts the values of a zapcore.MapObjectEncoder into the
+// shapes handled by Policy.Value.
+func normalize(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalize(item)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = normalize(item)
+		}
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return value
+	}
+}
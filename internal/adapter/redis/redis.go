@@ -5,6 +5,7 @@ import (
 
 	"github.com/redis/go-redis/extra/redisotel/v9"
 	grds "github.com/redis/go-redis/v9"
+	"github.com/widnyana/wasabi/internal/adapter/health"
 	"go.uber.org/fx"
 )
 
@@ -34,6 +35,9 @@ func Module(cfg Config) fx.Option {
 			func() Config { return cfg },
 			NewClient,
 			NewHealthChecker,
+			health.AsCheck(func(checker HealthChecker) health.Check {
+				return health.Check{Name: "redis", Checker: checker}
+			}),
 		),
 		fx.Invoke(
 			HookRedis,
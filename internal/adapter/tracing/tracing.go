@@ -3,13 +3,13 @@ package tracing
 import (
 	"context"
 
+	"github.com/widnyana/wasabi/internal/adapter/buildinfo"
+	"github.com/widnyana/wasabi/internal/adapter/redact"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/propagation"
-	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 )
@@ -34,14 +34,13 @@ var Module = fx.Module(
 		return otlptracegrpc.NewUnstarted(opts...)
 	}),
 
-	fx.Provide(func(config Config, exporter *otlptrace.Exporter) *sdktrace.TracerProvider {
+	// Spans are redacted by the *redact.Policy before they are batched
+	// for export.
+	fx.Provide(func(config Config, exporter *otlptrace.Exporter, redactor *redact.Policy) *sdktrace.TracerProvider {
 		provider := sdktrace.NewTracerProvider(
 			sdktrace.WithSampler(sdktrace.TraceIDRatioBased(config.SampleRate)),
-			sdktrace.WithBatcher(exporter),
-			sdktrace.WithResource(resource.NewWithAttributes(
-				semconv.SchemaURL,
-				semconv.ServiceNameKey.String("wasabi"),
-			)),
+			sdktrace.WithSpanProcessor(redact.NewSpanProcessor(redactor, sdktrace.NewBatchSpanProcessor(exporter))),
+			sdktrace.WithResource(buildinfo.Resource()),
 		)
 
 		otel.SetTracerProvider(provider)
@@ -7,10 +7,20 @@ import (
 	"strings"
 
 	"github.com/kelseyhightower/envconfig"
+	"github.com/widnyana/wasabi/internal/adapter/admin"
+	"github.com/widnyana/wasabi/internal/adapter/auth"
 	"github.com/widnyana/wasabi/internal/adapter/database/pg"
+	"github.com/widnyana/wasabi/internal/adapter/database/pg/migrate"
+	"github.com/widnyana/wasabi/internal/adapter/database/pg/notify"
 	"github.com/widnyana/wasabi/internal/adapter/http"
+	"github.com/widnyana/wasabi/internal/adapter/http/openapi"
+	"github.com/widnyana/wasabi/internal/adapter/httpcache"
+	"github.com/widnyana/wasabi/internal/adapter/idempotency"
+	"github.com/widnyana/wasabi/internal/adapter/jobs"
 	"github.com/widnyana/wasabi/internal/adapter/logger"
 	"github.com/widnyana/wasabi/internal/adapter/metrics"
+	"github.com/widnyana/wasabi/internal/adapter/outbox"
+	"github.com/widnyana/wasabi/internal/adapter/redact"
 	"github.com/widnyana/wasabi/internal/adapter/redis"
 	"github.com/widnyana/wasabi/internal/adapter/tracing"
 	"github.com/widnyana/wasabi/internal/constant"
@@ -20,13 +30,23 @@ var ErrParseConfigFailed = errors.New("failed to parse configuration from enviro
 
 // AppConfig contains structure of Application Config
 type AppConfig struct {
-	Env      string         `envconfig:"env"`
-	HTTP     http.Config    `envconfig:"http"`
-	Redis    redis.Config   `envconfig:"redis"`
-	Postgres pg.Config      `envconfig:"postgres"`
-	Metrics  metrics.Config `envconfig:"metrics"`
-	Tracing  tracing.Config `envconfig:"tracing"`
-	Log      logger.Config  `envconfig:"log"`
+	Env         string             `envconfig:"env"`
+	HTTP        http.Config        `envconfig:"http"`
+	Redis       redis.Config       `envconfig:"redis"`
+	Postgres    pg.Config          `envconfig:"postgres"`
+	Metrics     metrics.Config     `envconfig:"metrics"`
+	Tracing     tracing.Config     `envconfig:"tracing"`
+	Log         logger.Config      `envconfig:"log"`
+	Auth        auth.Config        `envconfig:"auth"`
+	Idempotency idempotency.Config `envconfig:"idempotency"`
+	HTTPCache   httpcache.Config   `envconfig:"http_cache"`
+	OpenAPI     openapi.Config     `envconfig:"openapi"`
+	Admin       admin.Config       `envconfig:"admin"`
+	Redact      redact.Config      `envconfig:"redact"`
+	Migrate     migrate.Config     `envconfig:"migrate"`
+	Outbox      outbox.Config      `envconfig:"outbox"`
+	Notify      notify.Config      `envconfig:"notify"`
+	Jobs        jobs.Config        `envconfig:"jobs"`
 }
 
 // NewAppConfig Provide a configuration instance
@@ -1,10 +1,20 @@
 package config
 
 import (
+	"github.com/widnyana/wasabi/internal/adapter/admin"
+	"github.com/widnyana/wasabi/internal/adapter/auth"
 	"github.com/widnyana/wasabi/internal/adapter/database/pg"
+	"github.com/widnyana/wasabi/internal/adapter/database/pg/migrate"
+	"github.com/widnyana/wasabi/internal/adapter/database/pg/notify"
 	"github.com/widnyana/wasabi/internal/adapter/http"
+	"github.com/widnyana/wasabi/internal/adapter/http/openapi"
+	"github.com/widnyana/wasabi/internal/adapter/httpcache"
+	"github.com/widnyana/wasabi/internal/adapter/idempotency"
+	"github.com/widnyana/wasabi/internal/adapter/jobs"
 	"github.com/widnyana/wasabi/internal/adapter/logger"
 	"github.com/widnyana/wasabi/internal/adapter/metrics"
+	"github.com/widnyana/wasabi/internal/adapter/outbox"
+	"github.com/widnyana/wasabi/internal/adapter/redact"
 	"github.com/widnyana/wasabi/internal/adapter/redis"
 	"github.com/widnyana/wasabi/internal/adapter/tracing"
 	"go.uber.org/fx"
@@ -30,5 +40,16 @@ var (
 		fx.Provide(func(config *AppConfig) metrics.Config { return config.Metrics }),
 		fx.Provide(func(config *AppConfig) tracing.Config { return config.Tracing }),
 		fx.Provide(func(config *AppConfig) logger.Config { return config.Log }),
+		fx.Provide(func(config *AppConfig) auth.Config { return config.Auth }),
+		fx.Provide(func(config *AppConfig) idempotency.Config { return config.Idempotency }),
+		fx.Provide(func(config *AppConfig) httpcache.Config { return config.HTTPCache }),
+		fx.Provide(func(config *AppConfig) openapi.Config { return config.OpenAPI }),
+		fx.Provide(func(config *AppConfig) admin.Config { return config.Admin }),
+		fx.Provide(func(config *AppConfig) redact.Config { return config.Redact }),
+		fx.Provide(func(config *AppConfig) migrate.Config { return config.Migrate }),
+		fx.Provide(func(config *AppConfig) outbox.Config { return config.Outbox }),
+		fx.Provide(func(config *AppConfig) notify.Config { return config.Notify }),
+		fx.Provide(func(config *AppConfig) jobs.Config { return config.Jobs }),
+		fx.Provide(func(config *AppConfig) *admin.ConfigSnapshot { return &admin.ConfigSnapshot{Value: config} }),
 	)
 )
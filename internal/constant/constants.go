@@ -14,17 +14,24 @@ const (
 	AppName = "wasabi"
 	// DefaultTimeout is the default timeout for http request
 	DefaultTimeout = 5 * time.Second
+
+	// DefaultAppVersion is the AppVersion of builds without ldflags.
+	DefaultAppVersion = "0.0.0"
+	// DefaultBuildtime is the Buildtime of builds without ldflags.
+	DefaultBuildtime = "2006 Jan 02 15:04:05"
+	// DefaultCommitHash is the CommitHash of builds without ldflags.
+	DefaultCommitHash = "0b00b135"
 )
 
 var (
 	// AppVersion is the version of the application.
-	AppVersion = "0.0.0"
+	AppVersion = DefaultAppVersion
 	// Branch is the git branch of the application
 	Branch = "main"
 	// Buildtime is the time when the application was built
-	Buildtime = "2006 Jan 02 15:04:05"
+	Buildtime = DefaultBuildtime
 	// CommitHash is the git commit hash of the application
-	CommitHash = "0b00b135"
+	CommitHash = DefaultCommitHash
 	// CommitMsg is the git commit message of the application.
 	CommitMsg = "n/a"
 	// ReleaseVersion is the release version of the application.